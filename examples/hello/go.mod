@@ -25,3 +25,9 @@ require (
 	golang.org/x/sys v0.5.0 // indirect
 	golang.org/x/text v0.7.0 // indirect
 )
+
+replace (
+	github.com/bongnv/sen/pkg/plugins/echo => ../../pkg/plugins/echo
+	github.com/bongnv/sen/pkg/plugins/zap => ../../pkg/plugins/zap
+	github.com/bongnv/sen/pkg/sen => ../../pkg/sen
+)
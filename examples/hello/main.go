@@ -32,10 +32,9 @@ func main() {
 // Service is an example implementation of a service.
 // Its dependencies are injected when it's registered to sen.
 type Service struct {
-	Hub    sen.Hub       `inject:"hub"`
-	Echo   *echo.Echo    `inject:"echo"`
-	Logger *zap.Logger   `inject:"logger"`
-	LC     sen.Lifecycle `inject:"lifecycle"`
+	Hub    sen.Hub     `inject:"hub"`
+	Echo   *echo.Echo  `inject:"echo"`
+	Logger *zap.Logger `inject:"logger"`
 }
 
 // Initialize initializes the service.
@@ -48,18 +47,15 @@ func (s *Service) Initialize() error {
 	// Following is an example of registering the handler for GET /hello
 	s.Echo.GET("/hello", s.Hello)
 
-	// Registers hooks at OnRun and OnShutdown.
-	// Not all services need these.
-	s.LC.OnRun(s.Run)
-	s.LC.OnShutdown(s.Shutdown)
-
 	// Registering the service under the name "my-service" so
 	// it can be injected as a dependency later on.
-	// It's also optional.
+	// As the service implements sen.Runner and sen.Stopper,
+	// Run and Stop are also attached to the application lifecycle.
 	return s.Hub.Register("my-service", s)
 }
 
 // Run is a hook when the application starts to run.
+// It implements sen.Runner so it's called automatically.
 // If is a long-running service, it should block the function from returning
 // until it finishes.
 //
@@ -69,11 +65,9 @@ func (s *Service) Run(_ context.Context) error {
 	return nil
 }
 
-// Shutdown is a hook when the application is shutting down. It's registered
-// to sen.Application via:
-//
-//	s.LC.OnShutdown(s.Shutdown)
-func (s *Service) Shutdown(_ context.Context) error {
+// Stop is a hook when the application is shutting down.
+// It implements sen.Stopper so it's called automatically.
+func (s *Service) Stop(_ context.Context) error {
 	s.Logger.Info("The service is shutting down")
 	return nil
 }
@@ -20,3 +20,5 @@ require (
 	golang.org/x/sys v0.5.0 // indirect
 	golang.org/x/text v0.7.0 // indirect
 )
+
+replace github.com/bongnv/sen/pkg/sen => ../../sen
@@ -16,9 +16,9 @@ import (
 //
 // # Usage
 //
-//	app.With(echo.Module())
+//	app.With(echo.Bundle())
 func Bundle() sen.Plugin {
-	return sen.Module(
+	return sen.Bundle(
 		&ConfigProvider{},
 		&Plugin{},
 	)
@@ -38,6 +38,15 @@ type ConfigProvider struct {
 	Hub sen.Hub `inject:"hub"`
 }
 
+// Describe describes the plugin.
+func (p ConfigProvider) Describe() sen.PluginInfo {
+	return sen.PluginInfo{
+		Name:     "echo-config",
+		Version:  Version,
+		Provides: []string{"echo.config"},
+	}
+}
+
 // Initialize loads Config from environment variables and
 // registers it to the application.
 func (p ConfigProvider) Initialize() error {
@@ -66,7 +75,17 @@ type Plugin struct {
 	Cfg *Config       `inject:"echo.config"`
 }
 
+// Describe describes the plugin.
+func (p Plugin) Describe() sen.PluginInfo {
+	return sen.PluginInfo{
+		Name:     "echo",
+		Version:  Version,
+		Provides: []string{"echo"},
+	}
+}
+
 // Initialize initializes and registers the echo.Echo instance with the provided middlewares.
+// The server starts listening in the OnRun phase, i.e. after all OnStart hooks succeed.
 func (p Plugin) Initialize() error {
 	e := echo.New()
 	e.Use(p.Middlewares...)
@@ -87,11 +106,11 @@ func (p Plugin) Initialize() error {
 		}
 
 		return nil
-	})
+	}, sen.WithHookName("echo"))
 
 	p.LC.OnShutdown(func(ctx context.Context) error {
 		return shutdownFn(ctx)
-	})
+	}, sen.WithHookName("echo"))
 
 	return p.Hub.Register("echo", e)
 }
@@ -53,7 +53,7 @@ func TestPlugin(t *testing.T) {
 
 		go func() {
 			err := app.Run(context.Background())
-			if fmt.Sprintf("%v", err) != "run error" {
+			if fmt.Sprintf("%v", err) != "run hook #1: run error" {
 				t.Errorf("Unexpected error: %v", err)
 			}
 			close(doneCh)
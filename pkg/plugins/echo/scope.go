@@ -0,0 +1,40 @@
+package echo
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+// ScopeKey is the key to store the request-scoped sen.Scope in echo.Context.
+const ScopeKey = "sen.scope"
+
+// ScopeMiddleware creates a middleware that creates a sen.Scope from the hub
+// for each HTTP request and stores it in echo.Context. The scope is disposed
+// after the request is handled.
+//
+// # Usage
+//
+//	e.Use(echo.ScopeMiddleware(hub))
+func ScopeMiddleware(hub sen.Hub) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			scope := hub.NewScope()
+			c.Set(ScopeKey, scope)
+			defer func() {
+				if err := scope.Dispose(c.Request().Context()); err != nil {
+					c.Logger().Error(err)
+				}
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+// Scope returns the request-scoped sen.Scope created by ScopeMiddleware.
+// It returns nil if ScopeMiddleware isn't used.
+func Scope(c echo.Context) sen.Scope {
+	scope, _ := c.Get(ScopeKey).(sen.Scope)
+	return scope
+}
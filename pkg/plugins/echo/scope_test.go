@@ -0,0 +1,67 @@
+package echo_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bongnv/sen/pkg/sen"
+	"github.com/labstack/echo/v4"
+
+	echoPlugin "github.com/bongnv/sen/pkg/plugins/echo"
+)
+
+func TestScopeMiddleware(t *testing.T) {
+	t.Run("should create a scope per request and dispose it afterwards", func(t *testing.T) {
+		app := sen.New()
+		m := &mockHubPlugin{}
+		if err := app.With(m); err != nil {
+			t.Errorf("Expected no error but got: %v", err)
+		}
+
+		disposed := false
+		e := echo.New()
+		e.Use(echoPlugin.ScopeMiddleware(m.Hub))
+		e.GET("/", func(c echo.Context) error {
+			scope := echoPlugin.Scope(c)
+			if scope == nil {
+				t.Errorf("Expected the scope to be populated")
+				return nil
+			}
+
+			scope.OnDispose(func(_ context.Context) error {
+				disposed = true
+				return nil
+			})
+
+			if err := scope.Register("request-id", "abc"); err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+
+			return c.NoContent(http.StatusOK)
+		})
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("Unexpected status code %v", rec.Code)
+		}
+
+		if !disposed {
+			t.Errorf("Expected the scope to be disposed")
+		}
+
+		if _, err := m.Hub.Retrieve("request-id"); err != sen.ErrComponentNotRegistered {
+			t.Errorf("Expected the request-scoped component isn't visible to the hub but got %v", err)
+		}
+	})
+}
+
+type mockHubPlugin struct {
+	Hub sen.Hub `inject:"hub"`
+}
+
+func (p mockHubPlugin) Initialize() error {
+	return nil
+}
@@ -0,0 +1,4 @@
+package echo
+
+// Version is the version of the plugin. It's updated by release-please.
+const Version = "0.2.0" // x-release-please-version
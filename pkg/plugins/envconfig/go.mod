@@ -7,4 +7,4 @@ require (
 	github.com/caarlos0/env/v8 v8.0.0
 )
 
-require golang.org/x/sync v0.2.0 // indirect
+replace github.com/bongnv/sen/pkg/sen => ../../sen
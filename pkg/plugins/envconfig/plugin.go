@@ -26,6 +26,16 @@ type provider struct {
 	cfg  any
 }
 
+// Describe describes the plugin. As the plugin can be applied multiple times,
+// it's named after the config.
+func (p *provider) Describe() sen.PluginInfo {
+	return sen.PluginInfo{
+		Name:     "envconfig:" + p.name,
+		Version:  Version,
+		Provides: []string{p.name},
+	}
+}
+
 // Initialize loads the config from environment variables and registers it to the Hub.
 func (p *provider) Initialize() error {
 	if err := env.Parse(p.cfg); err != nil {
@@ -20,3 +20,6 @@ require (
 	golang.org/x/sync v0.2.0 // indirect
 	golang.org/x/text v0.9.0 // indirect
 )
+
+replace github.com/bongnv/sen/pkg/sen => ../../sen
+replace github.com/bongnv/sen/pkg/plugins/envconfig => ../envconfig
@@ -36,13 +36,20 @@ type Plugin struct {
 	Config *Config `inject:"postgresgorm.config"`
 }
 
-// Initialize creates a new instance of *gorm.DB with the given configuration.
-// And then registers it into the application as `gorm`.
-func (p *Plugin) Initialize() error {
-	db, err := gorm.Open(postgres.Open(p.Config.DSN), &gorm.Config{})
-	if err != nil {
-		return err
+// Describe describes the plugin.
+func (p *Plugin) Describe() sen.PluginInfo {
+	return sen.PluginInfo{
+		Name:     "postgresgorm",
+		Version:  Version,
+		Provides: []string{"gorm"},
 	}
+}
 
-	return p.Hub.Register("gorm", db)
+// Initialize registers a constructor of *gorm.DB with the given configuration
+// into the application as `gorm`. The connection is only opened when
+// `gorm` is injected or retrieved for the first time.
+func (p *Plugin) Initialize() error {
+	return p.Hub.RegisterConstructor("gorm", func() (*gorm.DB, error) {
+		return gorm.Open(postgres.Open(p.Config.DSN), &gorm.Config{})
+	})
 }
@@ -0,0 +1,4 @@
+package postgresgorm
+
+// Version is the version of the plugin. It's updated by release-please.
+const Version = "0.1.0" // x-release-please-version
@@ -12,3 +12,5 @@ require (
 	go.uber.org/multierr v1.6.0 // indirect
 	golang.org/x/sync v0.2.0 // indirect
 )
+
+replace github.com/bongnv/sen/pkg/sen => ../../sen
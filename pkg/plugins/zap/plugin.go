@@ -22,8 +22,19 @@ type Plugin struct {
 	Hub sen.Hub       `inject:"hub"`
 }
 
+// Describe describes the plugin.
+func (p Plugin) Describe() sen.PluginInfo {
+	return sen.PluginInfo{
+		Name:     "zap",
+		Version:  Version,
+		Provides: []string{"logger"},
+	}
+}
+
 // Initialize initialises zap logger for the application.
 // The logger will be regisreted under "logger" tag.
+// Lifecycle hooks are logged as well, failed hooks are logged at error level,
+// restarted hooks are logged at warn level and others are logged at debug level.
 func (p Plugin) Initialize() error {
 	logger, err := zap.NewProduction(p.Options...)
 	if err != nil {
@@ -40,7 +51,31 @@ func (p Plugin) Initialize() error {
 		// Sync may return errors weirdly. It's best to ignore it for now. See https://github.com/uber-go/zap/issues/991
 		_ = logger.Sync()
 		return nil
-	})
+	}, sen.WithHookName("zap"))
+
+	p.LC.Observe(hookObserver(logger))
 
 	return p.Hub.Register("logger", logger)
 }
+
+// hookObserver logs lifecycle hook events via logger.
+func hookObserver(logger *zap.Logger) sen.HookObserver {
+	return func(e sen.HookEvent) {
+		fields := []zap.Field{
+			zap.String("phase", string(e.Phase)),
+			zap.String("hook", e.Name),
+			zap.String("owner", e.Owner),
+		}
+
+		switch e.Type {
+		case sen.HookStarted:
+			logger.Debug("Hook started", fields...)
+		case sen.HookFinished:
+			logger.Debug("Hook finished", append(fields, zap.Duration("duration", e.Duration))...)
+		case sen.HookFailed:
+			logger.Error("Hook failed", append(fields, zap.Duration("duration", e.Duration), zap.Error(e.Err))...)
+		case sen.HookRestarting:
+			logger.Warn("Hook restarting", append(fields, zap.Duration("delay", e.Duration), zap.Error(e.Err))...)
+		}
+	}
+}
@@ -2,9 +2,12 @@ package zap_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/bongnv/sen/pkg/sen"
 
@@ -38,4 +41,34 @@ func TestPlugin(t *testing.T) {
 			t.Errorf("Expected no error after running but got %v", runErr)
 		}
 	})
+
+	t.Run("should log failed lifecycle hooks", func(t *testing.T) {
+		core, logs := observer.New(zap.InfoLevel)
+		app := sen.New()
+		err := app.With(
+			&zapPlugin.Plugin{
+				Options: []zap.Option{
+					zap.WrapCore(func(zapcore.Core) zapcore.Core {
+						return core
+					}),
+				},
+			},
+			sen.OnShutdown(func(_ context.Context) error {
+				return errors.New("shutdown error")
+			}),
+		)
+		if err != nil {
+			t.Errorf("Expected no error but got: %v", err)
+		}
+
+		_ = app.Run(context.Background())
+		entries := logs.FilterMessage("Hook failed").All()
+		if len(entries) != 1 {
+			t.Fatalf("Expected one failed hook to be logged but got %v", logs.All())
+		}
+
+		if entries[0].ContextMap()["phase"] != "shutdown" {
+			t.Errorf("Unexpected log entry %v", entries[0].ContextMap())
+		}
+	})
 }
@@ -2,6 +2,10 @@ package sen
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
 )
 
 // Hook represents a hook to add custom logic in the application life cycle.
@@ -16,6 +20,13 @@ type Plugin interface {
 	Initialize() error
 }
 
+// ContextInitializer is implemented by plugins whose initialization can be cancelled.
+// If a plugin implements it, InitializeContext is called instead of Initialize
+// with the context given to WithContext and bounded by InitTimeout.
+type ContextInitializer interface {
+	InitializeContext(ctx context.Context) error
+}
+
 // Application represents an application.
 // To construct an application from plugins use: app.With. For example:
 //
@@ -24,19 +35,72 @@ type Plugin interface {
 //		handleError(err)
 //	}
 type Application struct {
-	hub Hub
-	lc  Lifecycle
+	hub *defaultHub
+	lc  *defaultLifecycle
+
+	sealHubOnRun bool
+	initTimeout  time.Duration
+
+	stateMu     sync.Mutex
+	state       State
+	subscribers []chan State
+	// started is closed once the application starts running or fails,
+	// so shutdown requests made before that can be honored.
+	started chan struct{}
+
+	inventory io.Writer
+	profiles  []string
+
+	// mu guards modules and plugins.
+	mu      sync.Mutex
+	modules []*defaultHub
+	plugins []appliedPlugin
+}
+
+// Option customizes an Application.
+type Option func(app *Application)
+
+// SealHubOnRun seals the hub when the application starts running
+// so registering components afterwards returns ErrHubSealed.
+// Scopes created from the hub aren't affected.
+func SealHubOnRun() Option {
+	return func(app *Application) {
+		app.sealHubOnRun = true
+	}
+}
+
+// InitTimeout bounds how long applying plugins via With or WithContext may take.
+// Plugins which don't implement ContextInitializer are abandoned once the timeout is reached.
+func InitTimeout(timeout time.Duration) Option {
+	return func(app *Application) {
+		app.initTimeout = timeout
+	}
+}
+
+// StartTimeout bounds how long OnStart hooks may take in total.
+// Once the timeout is reached, the running hook is abandoned, the remaining ones
+// aren't executed and the application shuts down.
+func StartTimeout(timeout time.Duration) Option {
+	return func(app *Application) {
+		app.lc.startTimeout = timeout
+	}
 }
 
 // New creates a new Application.
-func New() *Application {
+func New(opts ...Option) *Application {
 	app := &Application{
-		hub: newHub(),
-		lc:  newLifecycle(),
+		hub:     newHub(),
+		lc:      newLifecycle(),
+		started: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(app)
 	}
 
 	_ = app.hub.Register("app", app)
 	_ = app.hub.Register("lifecycle", app.lc)
+	app.lc.onPhase(app.trackLifecycle)
 
 	return app
 }
@@ -44,13 +108,72 @@ func New() *Application {
 // With applies a plugin or multiple plugins.
 // While applying a plugin, the plugin will be injected
 // with dependencies and Initialize method will be called.
+//
+// Plugins don't need to be given in the order of their dependencies.
+// A plugin is only initialized after all components it requires via
+// "inject" tags are registered, otherwise plugins are initialized
+// in the given order. Plugins in a Bundle are sorted together with the rest.
+// Plugins created via When are initialized after other plugins whose dependencies
+// are registered, so their conditions can see components of them. Plugins injecting
+// all matching components via "*,all" are initialized after the rest, so they can
+// collect their components.
+//
+// Components injected via optional tags are waited for if other plugins are going to
+// register them, i.e. plugins created via Component, Constructor, Module or implementing Describer.
+// If plugins depend on each other, the chain of their names is reported via DependencyReport.
+//
+// Plugins can only be applied before the application runs, otherwise ErrInvalidState is returned.
+// If a plugin fails, the application is failed and can't be used anymore.
 func (app *Application) With(plugins ...Plugin) error {
-	for _, p := range plugins {
-		if err := app.hub.Inject(p); err != nil {
-			return err
+	return app.WithContext(context.Background(), plugins...)
+}
+
+// WithContext applies plugins like With and gives ctx to plugins implementing ContextInitializer.
+// Once ctx is done or InitTimeout is reached, initializing plugins are abandoned
+// and an error wrapping the error of ctx is returned.
+//
+// # Usage
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := app.WithContext(ctx, plugin1, plugin2); err != nil {
+//		handleError(err)
+//	}
+func (app *Application) WithContext(ctx context.Context, plugins ...Plugin) error {
+	if err := app.transition("apply plugins to", StateInitializing, StateCreated, StateInitializing); err != nil {
+		return err
+	}
+
+	if app.initTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, app.initTimeout)
+		defer cancel()
+	}
+
+	if err := app.with(ctx, app.hub, plugins); err != nil {
+		app.setState(StateFailed)
+		return err
+	}
+
+	return nil
+}
+
+// with applies plugins into the given hub, which is either the hub of the application or a module.
+func (app *Application) with(ctx context.Context, hub *defaultHub, plugins []Plugin) error {
+	pending := flattenPlugins(plugins)
+	if err := validatePlugins(hub, app.appliedPlugins(), pending); err != nil {
+		return err
+	}
+
+	for len(pending) > 0 {
+		idx := nextReady(hub, pending)
+		if idx < 0 {
+			return newDependencyReport(hub, pending)
 		}
 
-		if err := p.Initialize(); err != nil {
+		p := pending[idx]
+		pending = append(pending[:idx:idx], pending[idx+1:]...)
+		if err := app.initializePlugin(ctx, hub, p); err != nil {
 			return err
 		}
 	}
@@ -60,11 +183,148 @@ func (app *Application) With(plugins ...Plugin) error {
 
 // Run runs the application by executing all run hooks in parallel.
 // After that it will execute shutdown hooks and afterRun hooks.
+//
+// Components registered into the hub are attached to the lifecycle automatically
+// if they implement Starter, Stopper or Runner. Starters are started in the order
+// of their dependencies before other OnStart hooks and started Stoppers are stopped
+// in the reverse order.
+//
+// An application can only run once, otherwise ErrInvalidState is returned.
+// The state of the application can be checked via State or Subscribe.
 func (app *Application) Run(ctx context.Context) error {
-	return app.lc.Run(ctx)
+	if err := app.transition("run", StateStarting, StateCreated, StateInitializing); err != nil {
+		return err
+	}
+
+	if app.inventory != nil {
+		if err := printInventory(app.inventory, app.appliedPlugins()); err != nil {
+			app.setState(StateFailed)
+			return err
+		}
+	}
+
+	if app.sealHubOnRun {
+		for _, hub := range app.hubs() {
+			hub.seal()
+		}
+	}
+
+	err := app.wireComponents()
+	if err == nil {
+		err = app.lc.Run(ctx)
+	}
+
+	if err != nil {
+		app.setState(StateFailed)
+		return err
+	}
+
+	app.setState(StateStopped)
+	return nil
 }
 
 // Shutdown runs the application by executing all the registered OnShutdown hooks.
+// If the application hasn't run yet, the shutdown is pending until Run starts,
+// so it's safe to call Shutdown right after starting Run in a goroutine.
 func (app *Application) Shutdown(ctx context.Context) error {
+	select {
+	case <-app.started:
+	case <-ctx.Done():
+		return fmt.Errorf("sen: unable to shut down the application while it's %s: %w", app.State(), ctx.Err())
+	}
+
 	return app.lc.Shutdown(ctx)
 }
+
+// Reload executes all the registered OnReload hooks, e.g. to re-read configurations.
+// Errors of the hooks are returned but the application keeps running.
+// It returns ErrInvalidState if the application isn't running.
+func (app *Application) Reload(ctx context.Context) error {
+	if err := app.require("reload", StateRunning); err != nil {
+		return err
+	}
+
+	return app.lc.Reload(ctx)
+}
+
+// initializePlugin injects dependencies into the plugin and initializes it.
+// Hooks registered while initializing the plugin are owned by the plugin.
+// The plugin is abandoned if ctx is done before it's initialized.
+func (app *Application) initializePlugin(ctx context.Context, hub *defaultHub, p Plugin) error {
+	if ctx.Done() == nil {
+		return app.setupPlugin(ctx, hub, p)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("sen: unable to initialize %s: %w", pluginName(p), err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.setupPlugin(ctx, hub, p)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("sen: unable to initialize %s: %w", pluginName(p), ctx.Err())
+	}
+}
+
+func (app *Application) setupPlugin(ctx context.Context, hub *defaultHub, p Plugin) error {
+	prevOwner := app.lc.setOwner(hookOwner(p))
+	defer app.lc.setOwner(prevOwner)
+
+	// lazy components are constructed while injecting so it may take time as well.
+	if err := hub.Inject(p); err != nil {
+		return withChain(err, pluginName(p))
+	}
+
+	if err := initialize(ctx, p); err != nil {
+		return withChain(err, pluginName(p))
+	}
+
+	// plugins only adding hooks aren't interesting in the inventory.
+	if !isHookPlugin(p) {
+		app.addPlugin(hub, describe(p))
+	}
+
+	return nil
+}
+
+func initialize(ctx context.Context, p Plugin) error {
+	if ci, ok := p.(ContextInitializer); ok {
+		return ci.InitializeContext(ctx)
+	}
+
+	return p.Initialize()
+}
+
+// addModule keeps track of the hub of a module.
+func (app *Application) addModule(hub *defaultHub) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.modules = append(app.modules, hub)
+}
+
+// hubs returns the hub of the application and hubs of all modules.
+func (app *Application) hubs() []*defaultHub {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return append([]*defaultHub{app.hub}, app.modules...)
+}
+
+// addPlugin keeps track of a plugin applied into the hub.
+func (app *Application) addPlugin(hub *defaultHub, info PluginInfo) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.plugins = append(app.plugins, appliedPlugin{hub: hub, info: info})
+}
+
+// appliedPlugins returns plugins applied into the application.
+func (app *Application) appliedPlugins() []appliedPlugin {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return append([]appliedPlugin(nil), app.plugins...)
+}
@@ -0,0 +1,253 @@
+package sen_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+type mockCyclicA struct {
+	B *mockCyclicB `inject:"b"`
+}
+
+type mockCyclicB struct {
+	A *mockCyclicA `inject:"a"`
+}
+
+type mockOptionalCyclicA struct {
+	B *mockOptionalCyclicB `inject:"b,optional"`
+}
+
+type mockOptionalCyclicB struct {
+	A *mockOptionalCyclicA `inject:"a"`
+}
+
+// mockXPlugin registers "x" without declaring it.
+type mockXPlugin struct {
+	Hub sen.Hub `inject:"hub"`
+	Y   int     `inject:"y"`
+}
+
+func (p *mockXPlugin) Initialize() error {
+	return p.Hub.Register("x", 1)
+}
+
+// mockYPlugin registers "y" without declaring it.
+type mockYPlugin struct {
+	Hub sen.Hub `inject:"hub"`
+	X   int     `inject:"x"`
+}
+
+func (p *mockYPlugin) Initialize() error {
+	return p.Hub.Register("y", 2)
+}
+
+type mockSlowPlugin struct {
+	blockCh chan struct{}
+}
+
+func (p *mockSlowPlugin) Initialize() error {
+	<-p.blockCh
+	return nil
+}
+
+type mockContextPlugin struct {
+	ctxErr chan error
+}
+
+func (p *mockContextPlugin) Initialize() error {
+	return errors.New("Initialize shouldn't be called")
+}
+
+func (p *mockContextPlugin) InitializeContext(ctx context.Context) error {
+	<-ctx.Done()
+	p.ctxErr <- ctx.Err()
+	return ctx.Err()
+}
+
+func TestApplication_With(t *testing.T) {
+	t.Run("should initialize plugins in the order of dependencies", func(t *testing.T) {
+		m := &mockPlugin{}
+		component := &mockComponent{}
+		app := sen.New()
+		err := app.With(
+			m,
+			sen.Component("need-data", component),
+			sen.Bundle(sen.Component("data", 10)),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if m.Data != 10 || component.Data != 10 {
+			t.Errorf("Unexpected data %v and %v", m.Data, component.Data)
+		}
+	})
+
+	t.Run("should initialize plugins injecting all components after their contributors", func(t *testing.T) {
+		namers := &mockNamers{}
+		app := sen.New()
+		err := app.With(
+			sen.Component("namers", namers),
+			sen.Component("a", &mockNamerImpl{name: "a"}),
+			sen.Bundle(sen.Component("b", &mockNamerImpl{name: "b"})),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if len(namers.List) != 2 || len(namers.Map) != 2 {
+			t.Errorf("Unexpected namers %v", namers.List)
+		}
+	})
+
+	t.Run("should report the chain of names if there is a dependency cycle", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			sen.Component("a", &mockCyclicA{}),
+			sen.Component("b", &mockCyclicB{}),
+		)
+		var report *sen.DependencyReport
+		if !errors.As(err, &report) {
+			t.Fatalf("Expected DependencyReport but got %v", err)
+		}
+
+		if strings.Join(report.Cycle, " -> ") != "a -> b -> a" {
+			t.Errorf("Unexpected cycle %v", report.Cycle)
+		}
+	})
+
+	t.Run("should initialize plugins after providers of their optional dependencies", func(t *testing.T) {
+		component := &mockOptionalComponent{}
+		app := sen.New()
+		err := app.With(
+			sen.Component("need-data", component),
+			sen.Component("data", 10),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if component.Data != 10 {
+			t.Errorf("Unexpected data %v", component.Data)
+		}
+	})
+
+	t.Run("should report cycles via optional dependencies", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			sen.Component("a", &mockOptionalCyclicA{}),
+			sen.Component("b", &mockOptionalCyclicB{}),
+		)
+		var report *sen.DependencyReport
+		if !errors.As(err, &report) {
+			t.Fatalf("Expected DependencyReport but got %v", err)
+		}
+
+		if strings.Join(report.Cycle, " -> ") != "a -> b -> a" {
+			t.Errorf("Unexpected cycle %v", report.Cycle)
+		}
+	})
+
+	t.Run("should report cycles among plugins not declaring what they register", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(&mockXPlugin{}, &mockYPlugin{})
+		var report *sen.DependencyReport
+		if !errors.As(err, &report) {
+			t.Fatalf("Expected DependencyReport but got %v", err)
+		}
+
+		if strings.Join(report.Cycle, " -> ") != "*sen_test.mockXPlugin -> *sen_test.mockYPlugin -> *sen_test.mockXPlugin" {
+			t.Errorf("Unexpected cycle %v", report.Cycle)
+		}
+	})
+
+	t.Run("should report all unresolved dependencies at once", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			&mockPlugin{},
+			sen.Bundle(sen.Component("need-data", &mockComponent{})),
+		)
+
+		expectedMsg := `sen: unable to resolve dependencies
+	hub: data is not registered (injecting *sen_test.mockPlugin.Data in *sen_test.mockPlugin)
+	hub: data is not registered (injecting *sen_test.mockComponent.Data in need-data)`
+		if fmt.Sprintf("%v", err) != expectedMsg {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if !errors.Is(err, sen.ErrComponentNotRegistered) {
+			t.Errorf("Expected ErrComponentNotRegistered but got %v", err)
+		}
+	})
+}
+
+func TestApplication_Timeouts(t *testing.T) {
+	t.Run("should abandon a slow plugin after the init timeout", func(t *testing.T) {
+		p := &mockSlowPlugin{blockCh: make(chan struct{})}
+		defer close(p.blockCh)
+
+		app := sen.New(sen.InitTimeout(10 * time.Millisecond))
+		err := app.With(p)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if fmt.Sprintf("%v", err) != "sen: unable to initialize *sen_test.mockSlowPlugin: context deadline exceeded" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should give the context to plugins implementing ContextInitializer", func(t *testing.T) {
+		p := &mockContextPlugin{ctxErr: make(chan error, 1)}
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		err := sen.New().WithContext(ctx, p)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if ctxErr := <-p.ctxErr; !errors.Is(ctxErr, context.Canceled) {
+			t.Errorf("Expected InitializeContext is called with ctx but got %v", ctxErr)
+		}
+	})
+
+	t.Run("should shut down if OnStart hooks take longer than the start timeout", func(t *testing.T) {
+		blockCh := make(chan struct{})
+		defer close(blockCh)
+
+		shutdownCalled := false
+		app := sen.New(sen.StartTimeout(10 * time.Millisecond))
+		err := app.With(
+			sen.OnStart(func(_ context.Context) error {
+				<-blockCh
+				return nil
+			}),
+			sen.OnShutdown(func(_ context.Context) error {
+				shutdownCalled = true
+				return nil
+			}),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err = app.Run(context.Background())
+		if fmt.Sprintf("%v", err) != "start hook #0: context deadline exceeded" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if !shutdownCalled {
+			t.Errorf("Expected shutdown hooks are called")
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package sen
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+)
+
+// Starter is implemented by components that need to be started
+// before the application runs, e.g. warming up a cache.
+// Start should return once the component is started.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper is implemented by components that need to be stopped
+// when the application shuts down, e.g. closing a connection pool.
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// Runner is implemented by long-running components, e.g. a server or a consumer.
+// Run should block until the component no longer runs.
+type Runner interface {
+	Run(ctx context.Context) error
+}
+
+var (
+	starterType = reflect.TypeOf((*Starter)(nil)).Elem()
+	stopperType = reflect.TypeOf((*Stopper)(nil)).Elem()
+	runnerType  = reflect.TypeOf((*Runner)(nil)).Elem()
+)
+
+// lifecycleComponents returns components registered into the hub that implement
+// Starter, Stopper or Runner in the order of their dependencies.
+// Lazy singletons implementing these interfaces are constructed so they can be started.
+// Components of modules are included as well.
+func (app *Application) lifecycleComponents() ([]*dependency, error) {
+	for _, hub := range app.hubs() {
+		for _, dep := range hub.ordered() {
+			if dep.constructor.IsValid() && dep.lifetime == Singleton && implementsLifecycle(dep.reflectType) {
+				if _, err := hub.instance(dep, &resolution{}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	var deps []*dependency
+	seen := map[*dependency]bool{}
+	for _, dep := range app.hub.instantiated() {
+		if dep.reflectType == nil || !implementsLifecycle(dep.reflectType) {
+			continue
+		}
+
+		// the application and its lifecycle are managed separately.
+		if seen[dep] || dep.value == app || dep.value == app.lc {
+			continue
+		}
+
+		seen[dep] = true
+		deps = append(deps, dep)
+	}
+
+	return deps, nil
+}
+
+// wireComponents attaches components implementing Starter, Stopper or Runner to the lifecycle.
+// Starters are started in the order of dependencies and Stoppers are stopped in the reverse order.
+// Only components that have been started are stopped. The context of Runners is canceled
+// once the application begins to shut down.
+// Hooks are named after the components.
+func (app *Application) wireComponents() error {
+	deps, err := app.lifecycleComponents()
+	if err != nil {
+		return err
+	}
+
+	var startHooks, stopHooks []*lifecycleHook
+	for i, dep := range deps {
+		name := dep.owner.prefix + dep.name
+		c := dep.reflectValue.Interface()
+		started := &atomic.Bool{}
+		startHooks = append(startHooks, newLifecycleHook(func(ctx context.Context) error {
+			if starter, ok := c.(Starter); ok {
+				if err := starter.Start(ctx); err != nil {
+					return err
+				}
+			}
+
+			started.Store(true)
+			return nil
+		}, PhaseStart, i, []HookOption{WithHookName(name)}))
+
+		if stopper, ok := c.(Stopper); ok {
+			stopHooks = append(stopHooks, newLifecycleHook(func(ctx context.Context) error {
+				if !started.Load() {
+					return nil
+				}
+
+				return stopper.Stop(ctx)
+			}, PhaseShutdown, i, []HookOption{WithHookName(name)}))
+		}
+
+		if runner, ok := c.(Runner); ok {
+			app.lc.add(&app.lc.runHooks, app.lc.untilShutdown(runner.Run), PhaseRun, []HookOption{WithHookName(name)})
+		}
+	}
+
+	app.lc.onStartFirst(startHooks...)
+	// components are stopped after other hooks as other hooks may depend on them.
+	app.lc.onShutdownLast(stopHooks...)
+	return nil
+}
+
+func implementsLifecycle(t reflect.Type) bool {
+	return t.Implements(starterType) || t.Implements(stopperType) || t.Implements(runnerType)
+}
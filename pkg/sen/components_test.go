@@ -0,0 +1,205 @@
+package sen_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+type mockRecorder struct {
+	events []string
+}
+
+func (r *mockRecorder) record(event string) {
+	r.events = append(r.events, event)
+}
+
+type mockDB struct {
+	Recorder *mockRecorder `inject:"recorder"`
+	startErr error
+}
+
+func (db *mockDB) Start(_ context.Context) error {
+	db.Recorder.record("db.start")
+	return db.startErr
+}
+
+func (db *mockDB) Stop(_ context.Context) error {
+	db.Recorder.record("db.stop")
+	return nil
+}
+
+type mockServer struct {
+	Recorder *mockRecorder `inject:"recorder"`
+	DB       *mockDB       `inject:"db"`
+}
+
+func (s *mockServer) Start(_ context.Context) error {
+	s.Recorder.record("server.start")
+	return nil
+}
+
+func (s *mockServer) Run(_ context.Context) error {
+	s.Recorder.record("server.run")
+	return nil
+}
+
+func (s *mockServer) Stop(_ context.Context) error {
+	s.Recorder.record("server.stop")
+	return nil
+}
+
+// mockWorker isn't comparable as it contains a slice.
+type mockWorker struct {
+	recorder *mockRecorder
+	queues   []string
+}
+
+func (w mockWorker) Start(_ context.Context) error {
+	w.recorder.record("worker.start")
+	return nil
+}
+
+// mockJob is comparable but its payload may not be hashable.
+type mockJob struct {
+	recorder *mockRecorder
+	payload  interface{}
+}
+
+func (j mockJob) Start(_ context.Context) error {
+	j.recorder.record("job.start")
+	return nil
+}
+
+type mockCache struct {
+	Recorder *mockRecorder `inject:"recorder"`
+}
+
+func (c *mockCache) Start(_ context.Context) error {
+	c.Recorder.record("cache.start")
+	return nil
+}
+
+func (c *mockCache) Stop(_ context.Context) error {
+	c.Recorder.record("cache.stop")
+	return nil
+}
+
+type mockConsumer struct {
+	Recorder *mockRecorder `inject:"recorder"`
+}
+
+func (c *mockConsumer) Run(ctx context.Context) error {
+	<-ctx.Done()
+	c.Recorder.record("consumer.stop")
+	return ctx.Err()
+}
+
+func TestLifecycleComponents(t *testing.T) {
+	t.Run("should start, run and stop components in the order of dependencies", func(t *testing.T) {
+		recorder := &mockRecorder{}
+		app := sen.New()
+		err := app.With(
+			sen.Constructor("server", func(db *mockDB) *mockServer {
+				return &mockServer{}
+			}),
+			sen.Component("db", &mockDB{}),
+			sen.Component("recorder", recorder),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		expected := "[db.start server.start server.run server.stop db.stop]"
+		if fmt.Sprint(recorder.events) != expected {
+			t.Errorf("Unexpected events %v", recorder.events)
+		}
+	})
+
+	t.Run("should stop started components if a component fails to start", func(t *testing.T) {
+		recorder := &mockRecorder{}
+		app := sen.New()
+		err := app.With(
+			sen.Component("recorder", recorder),
+			sen.Component("cache", &mockCache{}),
+			sen.Component("db", &mockDB{startErr: errors.New("start error")}),
+			sen.Component("server", &mockServer{}),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err = app.Run(context.Background())
+		if fmt.Sprintf("%v", err) != "start hook db: start error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if fmt.Sprint(recorder.events) != "[cache.start db.start cache.stop]" {
+			t.Errorf("Unexpected events %v", recorder.events)
+		}
+	})
+
+	t.Run("should start components which aren't comparable", func(t *testing.T) {
+		recorder := &mockRecorder{}
+		app := sen.New()
+		err := app.With(sen.Component("worker", mockWorker{recorder: recorder, queues: []string{"jobs"}}))
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if fmt.Sprint(recorder.events) != "[worker.start]" {
+			t.Errorf("Unexpected events %v", recorder.events)
+		}
+	})
+
+	t.Run("should start components whose values aren't hashable", func(t *testing.T) {
+		recorder := &mockRecorder{}
+		app := sen.New()
+		err := app.With(sen.Component("job", mockJob{recorder: recorder, payload: []string{"a"}}))
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if fmt.Sprint(recorder.events) != "[job.start]" {
+			t.Errorf("Unexpected events %v", recorder.events)
+		}
+	})
+
+	t.Run("should cancel the context of runners once the application shuts down", func(t *testing.T) {
+		recorder := &mockRecorder{}
+		app := sen.New()
+		err := app.With(
+			sen.Component("recorder", recorder),
+			sen.Component("consumer", &mockConsumer{}),
+			sen.OnRun(func(ctx context.Context) error {
+				return app.Shutdown(ctx)
+			}),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if fmt.Sprint(recorder.events) != "[consumer.stop]" {
+			t.Errorf("Unexpected events %v", recorder.events)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package sen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// profilesEnv is the environment variable listing active profiles, separated by commas.
+// It's used if profiles aren't given via Profiles.
+const profilesEnv = "SEN_PROFILES"
+
+// Condition decides whether plugins given to When are applied.
+// It's evaluated against the hub the plugins would be applied into.
+type Condition func(hub Hub) (bool, error)
+
+// Profiles activates profiles of the application which can be checked via Profile.
+// If it's not used, active profiles are read from the SEN_PROFILES environment variable,
+// e.g. SEN_PROFILES=production,tracing.
+func Profiles(names ...string) Option {
+	return func(app *Application) {
+		app.profiles = names
+	}
+}
+
+// When creates a plugin that applies plugins only if cond is satisfied.
+// The condition is evaluated after other plugins given to the same With call are initialized,
+// so HasComponent sees components registered by them regardless of the order of plugins.
+// Components registered by conditional plugins are only visible to conditions evaluated after them.
+//
+// # Usage
+//
+//	app.With(
+//		sen.When(sen.Profile("production"), postgresgorm.Bundle()),
+//		sen.When(sen.Not(sen.Profile("production")), sen.Component("gorm", inMemoryDB)),
+//	)
+func When(cond Condition, plugins ...Plugin) Plugin {
+	return &conditionalPlugin{
+		cond:    cond,
+		plugins: plugins,
+	}
+}
+
+type conditionalPlugin struct {
+	App *Application `inject:"app"`
+	Hub Hub          `inject:"hub"`
+
+	cond    Condition
+	plugins []Plugin
+}
+
+// Initialize applies plugins if the condition is satisfied.
+func (p *conditionalPlugin) Initialize() error {
+	return p.InitializeContext(context.Background())
+}
+
+// InitializeContext applies plugins with the given context if the condition is satisfied.
+func (p *conditionalPlugin) InitializeContext(ctx context.Context) error {
+	hub, ok := p.Hub.(*defaultHub)
+	if !ok {
+		return errors.New("sen: conditional plugins can only be applied via Application.With")
+	}
+
+	satisfied, err := p.cond(hub)
+	if err != nil {
+		return fmt.Errorf("sen: unable to evaluate condition: %w", err)
+	}
+
+	if !satisfied {
+		return nil
+	}
+
+	return p.App.with(ctx, hub, p.plugins)
+}
+
+// EnvEquals is satisfied if the environment variable key is set to value.
+func EnvEquals(key, value string) Condition {
+	return func(_ Hub) (bool, error) {
+		v, found := os.LookupEnv(key)
+		return found && v == value, nil
+	}
+}
+
+// EnvSet is satisfied if the environment variable key is set, even to an empty value.
+func EnvSet(key string) Condition {
+	return func(_ Hub) (bool, error) {
+		_, found := os.LookupEnv(key)
+		return found, nil
+	}
+}
+
+// HasComponent is satisfied if a component is registered under name.
+// The component isn't constructed if it's registered via a constructor.
+func HasComponent(name string) Condition {
+	return func(hub Hub) (bool, error) {
+		if h, ok := hub.(*defaultHub); ok {
+			_, found := h.lookup(name)
+			return found, nil
+		}
+
+		_, err := hub.Retrieve(name)
+		if errors.Is(err, ErrComponentNotRegistered) {
+			return false, nil
+		}
+
+		return err == nil, err
+	}
+}
+
+// Profile is satisfied if any of the given profiles is active.
+// See Profiles for how to activate profiles.
+func Profile(names ...string) Condition {
+	return func(hub Hub) (bool, error) {
+		app, err := ResolveNamed[*Application](hub, "app")
+		if err != nil {
+			return false, err
+		}
+
+		for _, active := range app.activeProfiles() {
+			for _, name := range names {
+				if active == name {
+					return true, nil
+				}
+			}
+		}
+
+		return false, nil
+	}
+}
+
+// Not negates a condition.
+func Not(cond Condition) Condition {
+	return func(hub Hub) (bool, error) {
+		satisfied, err := cond(hub)
+		return !satisfied, err
+	}
+}
+
+// activeProfiles returns profiles given via Profiles or SEN_PROFILES otherwise.
+func (app *Application) activeProfiles() []string {
+	if app.profiles != nil {
+		return app.profiles
+	}
+
+	var profiles []string
+	for _, name := range strings.Split(os.Getenv(profilesEnv), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			profiles = append(profiles, name)
+		}
+	}
+
+	return profiles
+}
@@ -0,0 +1,154 @@
+package sen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+type mockStoreConsumer struct {
+	Postgres bool   `inject:"postgres,optional"`
+	Memory   bool   `inject:"memory,optional"`
+	Empty    bool   `inject:"empty,optional"`
+	Unset    bool   `inject:"unset,optional"`
+	Repo     string `inject:"repo,optional"`
+}
+
+func TestWhen(t *testing.T) {
+	t.Run("should apply plugins only if the condition is satisfied", func(t *testing.T) {
+		component := &mockComponent{}
+		app := sen.New(sen.Profiles("production"))
+		err := app.With(
+			sen.Component("need-data", component),
+			sen.When(sen.Profile("production"), sen.Component("data", 10)),
+			sen.When(sen.Not(sen.Profile("production")), sen.Component("data", 20)),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if component.Data != 10 {
+			t.Errorf("Unexpected data %v", component.Data)
+		}
+	})
+
+	t.Run("should read profiles from SEN_PROFILES", func(t *testing.T) {
+		t.Setenv("SEN_PROFILES", "test, local")
+		component := &mockComponent{}
+		app := sen.New()
+		err := app.With(
+			sen.Component("need-data", component),
+			sen.When(sen.Profile("production"), sen.Component("data", 10)),
+			sen.When(sen.Profile("staging", "local"), sen.Component("data", 20)),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if component.Data != 20 {
+			t.Errorf("Unexpected data %v", component.Data)
+		}
+	})
+
+	t.Run("should check environment variables", func(t *testing.T) {
+		t.Setenv("SEN_TEST_STORE", "memory")
+		t.Setenv("SEN_TEST_EMPTY", "")
+		app := sen.New()
+		err := app.With(
+			sen.When(sen.EnvEquals("SEN_TEST_STORE", "postgres"), sen.Component("postgres", true)),
+			sen.When(sen.EnvEquals("SEN_TEST_STORE", "memory"), sen.Component("memory", true)),
+			sen.When(sen.EnvSet("SEN_TEST_EMPTY"), sen.Component("empty", true)),
+			sen.When(sen.EnvSet("SEN_TEST_UNSET"), sen.Component("unset", true)),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		consumer := &mockStoreConsumer{}
+		if err := app.With(sen.Component("consumer", consumer)); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		expected := mockStoreConsumer{Memory: true, Empty: true}
+		if *consumer != expected {
+			t.Errorf("Unexpected consumer %+v", *consumer)
+		}
+	})
+
+	t.Run("should check registered components without constructing them", func(t *testing.T) {
+		constructed := false
+		app := sen.New()
+		err := app.With(sen.Constructor("db", func() int {
+			constructed = true
+			return 1
+		}))
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err = app.With(
+			sen.When(sen.HasComponent("db"), sen.Component("repo", "postgres")),
+			sen.When(sen.Not(sen.HasComponent("db")), sen.Component("repo", "memory")),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		consumer := &mockStoreConsumer{}
+		if err := app.With(sen.Component("consumer", consumer)); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if consumer.Repo != "postgres" {
+			t.Errorf("Unexpected repo %v", consumer.Repo)
+		}
+
+		if constructed {
+			t.Errorf("db shouldn't be constructed")
+		}
+	})
+
+	t.Run("should evaluate conditions after other plugins regardless of their order", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			sen.When(sen.HasComponent("db"), sen.Component("repo", "postgres")),
+			sen.Component("db", 1),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		consumer := &mockStoreConsumer{}
+		if err := app.With(sen.Component("consumer", consumer)); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if consumer.Repo != "postgres" {
+			t.Errorf("Unexpected repo %v", consumer.Repo)
+		}
+	})
+
+	t.Run("should not report components of conditional plugins as missing", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			&mockDescribedPlugin{info: sen.PluginInfo{Name: "consumer", Requires: []string{"db"}}},
+			sen.When(sen.EnvSet("SEN_TEST_UNSET"), &mockDescribedPlugin{info: sen.PluginInfo{Name: "postgres", Provides: []string{"db"}}}),
+			sen.When(sen.Not(sen.EnvSet("SEN_TEST_UNSET")), &mockDescribedPlugin{info: sen.PluginInfo{Name: "memory", Provides: []string{"db"}}}),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should propagate error of the condition", func(t *testing.T) {
+		condErr := errors.New("condition error")
+		app := sen.New()
+		err := app.With(sen.When(func(_ sen.Hub) (bool, error) {
+			return false, condErr
+		}, sen.Component("data", 10)))
+		if !errors.Is(err, condErr) {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
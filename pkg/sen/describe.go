@@ -0,0 +1,237 @@
+package sen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// PluginInfo describes a plugin.
+type PluginInfo struct {
+	// Name is the name of the plugin. It should be unique in an application.
+	Name string
+	// Version is the version of the plugin, e.g. "0.2.0".
+	Version string
+	// Provides lists names of components that the plugin registers.
+	Provides []string
+	// Requires lists names of components that the plugin needs but doesn't declare
+	// via inject tags, e.g. components retrieved from the hub directly.
+	Requires []string
+	// RequiresPlugins maps names of plugins that the plugin depends on to version constraints,
+	// e.g. {"envconfig": ">=0.1.0, <1.0.0"}. An empty constraint accepts any version.
+	RequiresPlugins map[string]string
+}
+
+// Describer is implemented by plugins that describe themselves.
+// The description is used to validate plugins before they are initialized,
+// to order plugins by their dependencies and to print the plugin inventory.
+//
+// # Usage
+//
+//	func (p *Plugin) Describe() sen.PluginInfo {
+//		return sen.PluginInfo{
+//			Name:     "echo",
+//			Version:  Version,
+//			Provides: []string{"echo"},
+//		}
+//	}
+type Describer interface {
+	Describe() PluginInfo
+}
+
+// PrintInventory prints plugins applied into the application to w when it starts running.
+func PrintInventory(w io.Writer) Option {
+	return func(app *Application) {
+		app.inventory = w
+	}
+}
+
+// appliedPlugin is a plugin applied into a hub.
+type appliedPlugin struct {
+	hub  *defaultHub
+	info PluginInfo
+}
+
+// describe returns the information of a plugin. Plugins which don't implement
+// Describer are described by what the framework knows about them.
+func describe(p Plugin) PluginInfo {
+	if d, ok := p.(Describer); ok {
+		info := d.Describe()
+		if info.Name == "" {
+			info.Name = fmt.Sprintf("%T", p)
+		}
+
+		return info
+	}
+
+	info := PluginInfo{
+		Name: pluginName(p),
+	}
+
+	if pr, ok := p.(provider); ok {
+		info.Provides = pr.provides()
+	}
+
+	return info
+}
+
+// validatePlugins validates plugins to be applied into the hub against each other
+// and plugins that are already applied before any of them is initialized.
+// A plugin can only be applied once into a hub but it can be applied again into a module.
+// Required components must be registered or declared by plugins to be applied, unless
+// some of the plugins don't declare what they register as they may still register them.
+func validatePlugins(hub *defaultHub, applied []appliedPlugin, plugins []Plugin) error {
+	var errs []error
+	names := map[string]PluginInfo{}
+	inHub := map[string]bool{}
+	for _, a := range applied {
+		names[a.info.Name] = a.info
+		if a.hub == hub {
+			inHub[a.info.Name] = true
+		}
+	}
+
+	providers := map[string]string{}
+	for _, p := range plugins {
+		info := describe(p)
+		if _, ok := p.(Describer); ok {
+			if inHub[info.Name] {
+				errs = append(errs, fmt.Errorf("sen: plugin %s is applied more than once", info.Name))
+			}
+
+			names[info.Name] = info
+			inHub[info.Name] = true
+		}
+
+		for _, name := range info.Provides {
+			if other, found := providers[name]; found {
+				errs = append(errs, fmt.Errorf("sen: %s is provided by both %s and %s", name, other, info.Name))
+			}
+
+			providers[name] = info.Name
+		}
+	}
+
+	mayProvide, undeclared := mayRegister(plugins)
+	for _, p := range plugins {
+		info := describe(p)
+		for _, name := range info.Requires {
+			if mayProvide[name] || undeclared {
+				continue
+			}
+
+			if _, found := hub.lookup(name); !found {
+				errs = append(errs, fmt.Errorf("sen: plugin %s requires %s but it's not provided", info.Name, name))
+			}
+		}
+
+		for _, name := range sortedKeys(info.RequiresPlugins) {
+			constraint := info.RequiresPlugins[name]
+			required, found := names[name]
+			if !found {
+				errs = append(errs, fmt.Errorf("sen: plugin %s requires plugin %s but it's not applied", info.Name, name))
+				continue
+			}
+
+			if constraint == "" {
+				continue
+			}
+
+			ok, err := satisfiesVersion(required.Version, constraint)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("sen: unable to check plugin %s required by %s: %w", name, info.Name, err))
+				continue
+			}
+
+			if !ok {
+				errs = append(errs, fmt.Errorf("sen: plugin %s requires plugin %s %s but got %s", info.Name, name, constraint, required.Version))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// mayRegister returns names of components that plugins declare to register,
+// including ones of conditional plugins which may not be applied.
+// It also reports whether some plugins don't declare what they register,
+// so any component may be registered.
+func mayRegister(plugins []Plugin) (map[string]bool, bool) {
+	names := map[string]bool{}
+	undeclared := false
+	for _, p := range plugins {
+		if c, ok := p.(*conditionalPlugin); ok {
+			conditionalNames, conditionalUndeclared := mayRegister(flattenPlugins(c.plugins))
+			for name := range conditionalNames {
+				names[name] = true
+			}
+
+			undeclared = undeclared || conditionalUndeclared
+			continue
+		}
+
+		if !declares(p) && !registersNothing(p) {
+			undeclared = true
+			continue
+		}
+
+		for _, name := range describe(p).Provides {
+			names[name] = true
+		}
+	}
+
+	return names, undeclared
+}
+
+// registersNothing reports whether a built-in plugin never registers any component.
+func registersNothing(p Plugin) bool {
+	if _, ok := p.(overrider); ok {
+		return true
+	}
+
+	_, ok := p.(*gracefulShutdownPlugin)
+	return ok || isHookPlugin(p)
+}
+
+// printInventory prints plugins as a table. Plugins in modules are prefixed by their modules.
+func printInventory(w io.Writer, plugins []appliedPlugin) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PLUGIN\tVERSION\tPROVIDES\tREQUIRES")
+	for _, a := range plugins {
+		info := a.info
+		version := info.Version
+		if version == "" {
+			version = "-"
+		}
+
+		requires := append([]string(nil), info.Requires...)
+		for _, name := range sortedKeys(info.RequiresPlugins) {
+			requires = append(requires, strings.TrimSpace("plugin "+name+" "+info.RequiresPlugins[name]))
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.hub.prefix+info.Name, version, joinOrDash(info.Provides), joinOrDash(requires))
+	}
+
+	return tw.Flush()
+}
+
+func joinOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+
+	return strings.Join(values, ", ")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,167 @@
+package sen_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+type mockDescribedPlugin struct {
+	info sen.PluginInfo
+	Hub  sen.Hub `inject:"hub"`
+}
+
+func (p *mockDescribedPlugin) Describe() sen.PluginInfo {
+	return p.info
+}
+
+func (p *mockDescribedPlugin) Initialize() error {
+	for _, name := range p.info.Provides {
+		if err := p.Hub.Register(name, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mockCachePlugin registers a component without describing it.
+type mockCachePlugin struct {
+	Hub sen.Hub `inject:"hub"`
+}
+
+func (p *mockCachePlugin) Initialize() error {
+	return p.Hub.Register("cache", "cache")
+}
+
+func TestDescriber(t *testing.T) {
+	t.Run("should order plugins by their declared requirements", func(t *testing.T) {
+		consumer := &mockDescribedPlugin{info: sen.PluginInfo{Name: "consumer", Requires: []string{"db"}}}
+		app := sen.New()
+		err := app.With(
+			consumer,
+			&mockDescribedPlugin{info: sen.PluginInfo{Name: "database", Provides: []string{"db"}}},
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should validate plugins before initializing them", func(t *testing.T) {
+		initialized := false
+		app := sen.New()
+		err := app.With(
+			sen.OnStart(func(_ context.Context) error {
+				initialized = true
+				return nil
+			}),
+			&mockDescribedPlugin{info: sen.PluginInfo{Name: "a", Provides: []string{"db"}}},
+			&mockDescribedPlugin{info: sen.PluginInfo{Name: "a", Provides: []string{"db"}}},
+			&mockDescribedPlugin{info: sen.PluginInfo{Name: "b", Requires: []string{"cache"}}},
+			&mockDescribedPlugin{info: sen.PluginInfo{Name: "c", RequiresPlugins: map[string]string{"config": ""}}},
+		)
+
+		expectedErr := "sen: plugin a is applied more than once\n" +
+			"sen: db is provided by both a and a\n" +
+			"sen: plugin b requires cache but it's not provided\n" +
+			"sen: plugin c requires plugin config but it's not applied"
+		if fmt.Sprintf("%v", err) != expectedErr {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if initialized {
+			t.Errorf("Expected no plugin is initialized")
+		}
+	})
+
+	t.Run("should wait for required components registered by plugins without descriptions", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			&mockDescribedPlugin{info: sen.PluginInfo{Name: "consumer", Requires: []string{"cache"}}},
+			&mockCachePlugin{},
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should report required components that are never registered", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			&mockDescribedPlugin{info: sen.PluginInfo{Name: "consumer", Requires: []string{"queue"}}},
+			&mockCachePlugin{},
+		)
+		if !errors.Is(err, sen.ErrComponentNotRegistered) {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should check version constraints of required plugins", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(&mockDescribedPlugin{info: sen.PluginInfo{Name: "config", Version: "0.1.0"}})
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err = app.With(&mockDescribedPlugin{info: sen.PluginInfo{
+			Name:            "db",
+			RequiresPlugins: map[string]string{"config": ">=0.2.0, <1.0.0"},
+		}})
+		if fmt.Sprintf("%v", err) != "sen: plugin db requires plugin config >=0.2.0, <1.0.0 but got 0.1.0" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should allow applying a plugin again in a module", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			sen.Module("payments", &mockDescribedPlugin{info: sen.PluginInfo{Name: "database", Provides: []string{"db"}}}),
+			sen.Module("orders", &mockDescribedPlugin{info: sen.PluginInfo{Name: "database", Provides: []string{"db"}}}),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should print the plugin inventory when the application runs", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		app := sen.New(sen.PrintInventory(out))
+		err := app.With(
+			&mockDescribedPlugin{info: sen.PluginInfo{Name: "config", Version: "0.1.0", Provides: []string{"config"}}},
+			&mockDescribedPlugin{info: sen.PluginInfo{
+				Name:            "database",
+				Version:         "0.2.0",
+				Provides:        []string{"db"},
+				RequiresPlugins: map[string]string{"config": ">=0.1.0"},
+			}},
+			sen.Module("payments", sen.Component("data", 10)),
+			sen.OnRun(func(_ context.Context) error {
+				return nil
+			}),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		expected := strings.Join([]string{
+			"PLUGIN           VERSION  PROVIDES  REQUIRES",
+			"config           0.1.0    config    -",
+			"database         0.2.0    db        plugin config >=0.1.0",
+			"payments.data    -        data      -",
+			"module payments  -        -         -",
+			"",
+		}, "\n")
+		if out.String() != expected {
+			t.Errorf("Unexpected inventory\n%s", out.String())
+		}
+	})
+}
@@ -0,0 +1,225 @@
+package sen
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrComponentNotRegistered is returned when the expected component isn't registered
+// so it couldn't be found by name.
+var ErrComponentNotRegistered = errors.New("sen: the component is not registered")
+
+// ErrHookTimeout is returned when a hook doesn't return before its timeout.
+var ErrHookTimeout = errors.New("sen: the hook timed out")
+
+// ErrInvalidState is returned when an operation isn't allowed in the current state
+// of the application, e.g. calling Run twice.
+var ErrInvalidState = errors.New("sen: invalid application state")
+
+// ErrHubSealed is returned when a component is registered after the hub is sealed.
+var ErrHubSealed = errors.New("sen: the hub is sealed")
+
+// notRegisteredError describes a missing dependency.
+// It matches ErrComponentNotRegistered via errors.Is.
+type notRegisteredError struct {
+	msg string
+}
+
+func (e *notRegisteredError) Error() string {
+	return e.msg
+}
+
+func (e *notRegisteredError) Is(target error) bool {
+	return target == ErrComponentNotRegistered
+}
+
+// InjectionError is returned when a dependency couldn't be injected into a field.
+type InjectionError struct {
+	// Type is the type of the component requesting the dependency.
+	Type reflect.Type
+	// Field is the name of the field requesting the dependency.
+	// It's empty if the dependency is required via Describer.
+	Field string
+	// Tag is the value of the inject tag of the field.
+	Tag string
+	// Dependency is the name of the requested dependency or its type name
+	// if it's injected by type.
+	Dependency string
+	// Chain is the chain of plugins involved, from the outermost one.
+	Chain []string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *InjectionError) Error() string {
+	msg := fmt.Sprintf("%v (injecting %s.%s", e.Err, typeString(e.Type), e.Field)
+	if e.Field == "" {
+		// the dependency is declared via Describer instead of a field.
+		msg = fmt.Sprintf("%v (required by %s", e.Err, typeString(e.Type))
+	}
+
+	if len(e.Chain) > 0 {
+		msg += " in " + strings.Join(e.Chain, " -> ")
+	}
+
+	return msg + ")"
+}
+
+// Unwrap returns the underlying error.
+func (e *InjectionError) Unwrap() error {
+	return e.Err
+}
+
+// DependencyReport is returned when plugins can't be initialized because of
+// unresolved dependencies. It lists all of them at once.
+type DependencyReport struct {
+	// Cycle is the chain of plugins forming a dependency cycle if there is one.
+	Cycle []string
+	// Errors contains every unresolved dependency.
+	Errors []*InjectionError
+}
+
+// Error implements the error interface.
+func (r *DependencyReport) Error() string {
+	var b strings.Builder
+	b.WriteString("sen: unable to resolve dependencies")
+	if len(r.Cycle) > 0 {
+		b.WriteString("\n\tdependency cycle detected: ")
+		b.WriteString(strings.Join(r.Cycle, " -> "))
+	}
+
+	for _, err := range r.Errors {
+		b.WriteString("\n\t")
+		b.WriteString(err.Error())
+	}
+
+	return b.String()
+}
+
+// Unwrap returns all unresolved dependencies as errors.
+func (r *DependencyReport) Unwrap() []error {
+	errs := make([]error, len(r.Errors))
+	for i, err := range r.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// withChain prepends the name of a plugin into the chain of injection errors.
+func withChain(err error, name string) error {
+	var report *DependencyReport
+	if errors.As(err, &report) {
+		for _, injectionErr := range report.Errors {
+			injectionErr.Chain = append([]string{name}, injectionErr.Chain...)
+		}
+
+		return err
+	}
+
+	var injectionErr *InjectionError
+	if errors.As(err, &injectionErr) {
+		injectionErr.Chain = append([]string{name}, injectionErr.Chain...)
+	}
+
+	return err
+}
+
+// HookError describes a failure of a lifecycle hook.
+type HookError struct {
+	// Phase is the phase the hook belongs to.
+	Phase Phase
+	// Name is the name of the hook.
+	Name string
+	// Owner is the plugin registering the hook. It's empty if the hook
+	// isn't registered by a plugin.
+	Owner string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *HookError) Error() string {
+	if e.Owner != "" {
+		return fmt.Sprintf("%s hook %s of %s: %v", e.Phase, e.Name, e.Owner, e.Err)
+	}
+
+	return fmt.Sprintf("%s hook %s: %v", e.Phase, e.Name, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is returned when a lifecycle hook panics.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine where the panic happened.
+	Stack []byte
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("sen: recovered from panic: %v\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the value passed to panic if it's an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// LifecycleError aggregates errors of all failed hooks while running an application.
+// It works with errors.Is and errors.As as errors.Join does.
+type LifecycleError struct {
+	// Errors contains errors of failed hooks in the order of phases,
+	// they are usually *HookError.
+	Errors []error
+}
+
+// Error implements the error interface.
+func (e *LifecycleError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns errors of all failed hooks.
+func (e *LifecycleError) Unwrap() []error {
+	return e.Errors
+}
+
+// newLifecycleError creates a *LifecycleError from errs.
+// It returns nil if there is no error.
+func newLifecycleError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &LifecycleError{
+		Errors: errs,
+	}
+}
+
+// appendErrors appends err into errs. If err is a *LifecycleError,
+// its errors are appended instead.
+func appendErrors(errs []error, err error) []error {
+	if err == nil {
+		return errs
+	}
+
+	var lcErr *LifecycleError
+	if errors.As(err, &lcErr) {
+		return append(errs, lcErr.Errors...)
+	}
+
+	return append(errs, err)
+}
@@ -10,6 +10,11 @@ import (
 func Example() {
 	app := sen.New()
 
+	startHook := sen.OnStart(func(_ context.Context) error {
+		fmt.Println("OnStart is executed")
+		return nil
+	})
+
 	runHook := sen.OnRun(func(_ context.Context) error {
 		fmt.Println("OnRun is executed")
 		return nil
@@ -25,13 +30,14 @@ func Example() {
 		return nil
 	})
 
-	_ = app.With(runHook, shutdownHook, postRunHook)
+	_ = app.With(startHook, runHook, shutdownHook, postRunHook)
 	err := app.Run(context.Background())
 	if err != nil {
 		fmt.Println(err)
 	}
 
 	// Output:
+	// OnStart is executed
 	// OnRun is executed
 	// OnShutdown is executed
 	// PostRun is executed
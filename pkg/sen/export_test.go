@@ -5,3 +5,15 @@ var NewHub = newHub
 
 // NewLifecycle exports newLifecycle for testing.
 var NewLifecycle = newLifecycle
+
+// SetOSExit replaces osExit for testing and returns a function to restore it.
+func SetOSExit(fn func(code int)) func() {
+	prev := osExit
+	osExit = fn
+	return func() {
+		osExit = prev
+	}
+}
+
+// SatisfiesVersion exports satisfiesVersion for testing.
+var SatisfiesVersion = satisfiesVersion
@@ -1,5 +1,3 @@
 module github.com/bongnv/sen/pkg/sen
 
-go 1.18
-
-require golang.org/x/sync v0.2.0
+go 1.20
@@ -2,38 +2,167 @@ package sen
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
+// osExit is used to force exiting the process. It's replaced in tests.
+var osExit = os.Exit
+
+// GracefulShutdownOption customizes the GracefulShutdown plugin.
+type GracefulShutdownOption func(p *gracefulShutdownPlugin)
+
+// WithSignals specifies signals to shut down the application.
+// By default, they are os.Interrupt and syscall.SIGTERM.
+// At least one signal must be given, otherwise the plugin fails to initialize.
+func WithSignals(signals ...os.Signal) GracefulShutdownOption {
+	return func(p *gracefulShutdownPlugin) {
+		p.signals = signals
+	}
+}
+
+// WithReloadSignals specifies signals to reload the application via OnReload hooks,
+// e.g. syscall.SIGHUP. Reloading via signals is disabled by default so the default
+// behavior of the signals is kept.
+func WithReloadSignals(signals ...os.Signal) GracefulShutdownOption {
+	return func(p *gracefulShutdownPlugin) {
+		p.reloadSignals = signals
+	}
+}
+
+// WithShutdownTimeout bounds the whole shutdown phase. The context given
+// to OnShutdown hooks is canceled after the timeout, the running hook is abandoned
+// and the remaining ones are skipped with ErrHookTimeout.
+func WithShutdownTimeout(timeout time.Duration) GracefulShutdownOption {
+	return func(p *gracefulShutdownPlugin) {
+		p.timeout = timeout
+	}
+}
+
+// WithShutdownDelay delays shutting down the application after a signal is received,
+// e.g. to wait for the instance being deregistered from load balancers.
+func WithShutdownDelay(delay time.Duration) GracefulShutdownOption {
+	return func(p *gracefulShutdownPlugin) {
+		p.delay = delay
+	}
+}
+
+// WithForceExitCode specifies the exit code when the process is forced to exit
+// because a signal is received again while the application is shutting down.
+// It's 1 by default.
+func WithForceExitCode(code int) GracefulShutdownOption {
+	return func(p *gracefulShutdownPlugin) {
+		p.forceExitCode = code
+	}
+}
+
 // GracefulShutdown creates a new GracefulShutdownPlugin.
 // The plugin will allow the application calling its Shutdown
-// when an interrupt signal (Ctrl+C) is received.
-func GracefulShutdown() Plugin {
-	return &gracefulShutdownPlugin{}
+// when an interrupt signal (Ctrl+C) or SIGTERM is received.
+// If the signal is received again while the application is shutting down,
+// the process exits immediately with a non-zero code.
+// Signals given via WithReloadSignals reload the application via OnReload hooks,
+// errors of the hooks are sent to lifecycle observers and don't stop the application.
+//
+// # Usage
+//
+//	app.With(sen.GracefulShutdown(
+//		sen.WithShutdownDelay(5*time.Second),
+//		sen.WithShutdownTimeout(30*time.Second),
+//	))
+func GracefulShutdown(opts ...GracefulShutdownOption) Plugin {
+	p := &gracefulShutdownPlugin{
+		signals:       []os.Signal{os.Interrupt, syscall.SIGTERM},
+		forceExitCode: 1,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 // gracefulShutdownPlugin is a plugin to allow the application to receive SIGTERM signal
 // and shuts down the application gracefully.
 type gracefulShutdownPlugin struct {
 	Lifecycle Lifecycle `inject:"lifecycle"`
+
+	signals       []os.Signal
+	reloadSignals []os.Signal
+	timeout       time.Duration
+	delay         time.Duration
+	forceExitCode int
 }
 
 func (s *gracefulShutdownPlugin) Initialize() error {
+	// signal.Notify without signals relays all of them, including ones used by the runtime.
+	if len(s.signals) == 0 {
+		return errors.New("sen: GracefulShutdown requires at least one signal to shut down the application")
+	}
+
 	exit := make(chan os.Signal, 1)
-	signal.Notify(exit, os.Interrupt, syscall.SIGTERM)
-
-	s.Lifecycle.OnRun(func(ctx context.Context) error {
-		go func() {
-			select {
-			case <-exit:
-				_ = s.Lifecycle.Shutdown(ctx)
-			case <-ctx.Done():
-			}
-		}()
+	signal.Notify(exit, s.signals...)
+	reload := make(chan os.Signal, 1)
+	if len(s.reloadSignals) > 0 {
+		signal.Notify(reload, s.reloadSignals...)
+	}
+
+	done := make(chan struct{})
+
+	// signals are watched until PostRun hooks so a repeated signal while
+	// shutting down can force exiting the process.
+	s.Lifecycle.OnStart(func(_ context.Context) error {
+		go s.watch(exit, reload, done)
 		return nil
-	})
+	}, WithHookName("graceful-shutdown"))
+
+	s.Lifecycle.PostRun(func(_ context.Context) error {
+		signal.Stop(exit)
+		signal.Stop(reload)
+		close(done)
+		return nil
+	}, WithHookName("graceful-shutdown"))
 
 	return nil
 }
+
+func (s *gracefulShutdownPlugin) watch(exit, reload <-chan os.Signal, done <-chan struct{}) {
+	for stopping := false; !stopping; {
+		select {
+		case <-exit:
+			stopping = true
+		case <-reload:
+			// errors are reported to observers so they are ignored here.
+			_ = s.Lifecycle.Reload(context.Background())
+		case <-done:
+			return
+		}
+	}
+
+	go s.shutdown()
+
+	select {
+	case <-exit:
+		osExit(s.forceExitCode)
+	case <-done:
+	}
+}
+
+func (s *gracefulShutdownPlugin) shutdown() {
+	if s.delay > 0 {
+		time.Sleep(s.delay)
+	}
+
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	_ = s.Lifecycle.Shutdown(ctx)
+}
@@ -2,6 +2,8 @@ package sen_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"syscall"
 	"testing"
 	"time"
@@ -119,4 +121,184 @@ func TestGracefulShutdown(t *testing.T) {
 			t.Errorf("test timed out")
 		}
 	})
+
+	t.Run("should shut down the app with the given signals", func(t *testing.T) {
+		m := makeMockWaitingPlugin()
+		app := sen.New()
+		err := app.With(
+			sen.GracefulShutdown(sen.WithSignals(syscall.SIGUSR1)),
+			m,
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		doneCh := make(chan error)
+		go func() {
+			doneCh <- app.Run(context.Background())
+		}()
+
+		<-m.ready
+		err = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		select {
+		case err := <-doneCh:
+			if err != nil {
+				t.Errorf("Unexpected err %v", err)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("test timed out")
+		}
+	})
+
+	t.Run("should return error if no signal is given", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(sen.GracefulShutdown(sen.WithSignals()))
+		if fmt.Sprintf("%v", err) != "sen: GracefulShutdown requires at least one signal to shut down the application" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should wait for the delay before shutting down", func(t *testing.T) {
+		m := makeMockWaitingPlugin()
+		app := sen.New()
+		err := app.With(
+			sen.GracefulShutdown(sen.WithShutdownDelay(50*time.Millisecond)),
+			m,
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		doneCh := make(chan error)
+		go func() {
+			doneCh <- app.Run(context.Background())
+		}()
+
+		<-m.ready
+		startedAt := time.Now()
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		select {
+		case <-doneCh:
+			if time.Since(startedAt) < 50*time.Millisecond {
+				t.Errorf("Expected the shutdown is delayed")
+			}
+		case <-time.After(200 * time.Millisecond):
+			t.Errorf("test timed out")
+		}
+	})
+
+	t.Run("should bound the shutdown phase with the timeout", func(t *testing.T) {
+		m := makeMockWaitingPlugin()
+		blockCh := make(chan struct{})
+		defer close(blockCh)
+		app := sen.New()
+		err := app.With(
+			sen.GracefulShutdown(sen.WithShutdownTimeout(20*time.Millisecond)),
+			m,
+			sen.OnShutdown(func(_ context.Context) error {
+				<-blockCh
+				return nil
+			}),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		doneCh := make(chan error)
+		go func() {
+			doneCh <- app.Run(context.Background())
+		}()
+
+		<-m.ready
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		select {
+		case err := <-doneCh:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Unexpected err %v", err)
+			}
+		case <-time.After(200 * time.Millisecond):
+			t.Errorf("test timed out")
+		}
+	})
+
+	t.Run("should force exiting if the signal is received again", func(t *testing.T) {
+		exitCh := make(chan int, 1)
+		restore := sen.SetOSExit(func(code int) {
+			exitCh <- code
+		})
+		defer restore()
+
+		m := makeMockWaitingPlugin()
+		app := sen.New()
+		err := app.With(
+			sen.GracefulShutdown(sen.WithShutdownDelay(time.Second), sen.WithForceExitCode(2)),
+			m,
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		go func() {
+			_ = app.Run(context.Background())
+		}()
+
+		<-m.ready
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		time.Sleep(10 * time.Millisecond)
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		select {
+		case code := <-exitCh:
+			if code != 2 {
+				t.Errorf("Unexpected exit code %d", code)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("test timed out")
+		}
+
+		_ = app.Shutdown(context.Background())
+	})
+
+	t.Run("should reload the app if a reload signal is received", func(t *testing.T) {
+		m := makeMockWaitingPlugin()
+		reloadCh := make(chan struct{})
+		app := sen.New()
+		err := app.With(
+			sen.GracefulShutdown(sen.WithReloadSignals(syscall.SIGHUP)),
+			m,
+			sen.OnReload(func(_ context.Context) error {
+				close(reloadCh)
+				return errors.New("reload error")
+			}),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		doneCh := make(chan error)
+		go func() {
+			doneCh <- app.Run(context.Background())
+		}()
+
+		<-m.ready
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+		select {
+		case <-reloadCh:
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("test timed out")
+		}
+
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		select {
+		case err := <-doneCh:
+			if err != nil {
+				t.Errorf("Unexpected err %v", err)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("test timed out")
+		}
+	})
 }
@@ -0,0 +1,311 @@
+package sen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// provider is implemented by plugins which know the names of components
+// they register before being initialized.
+type provider interface {
+	provides() []string
+}
+
+// dependent is implemented by plugins which depend on components that aren't
+// declared via their own inject tags, e.g. the component of a componentPlugin.
+type dependent interface {
+	dependsOn() []interface{}
+}
+
+// overrider is implemented by plugins which override a registered component, e.g. Replace.
+type overrider interface {
+	overrides() string
+}
+
+// flattenPlugins expands bundles so their plugins can be sorted together
+// with the rest of the plugins.
+func flattenPlugins(plugins []Plugin) []Plugin {
+	flattened := make([]Plugin, 0, len(plugins))
+	for _, p := range plugins {
+		if b, ok := p.(*bundlePlugin); ok {
+			flattened = append(flattened, flattenPlugins(b.plugins)...)
+			continue
+		}
+
+		flattened = append(flattened, p)
+	}
+
+	return flattened
+}
+
+// missingDeps returns all dependencies of a plugin that
+// haven't been registered into the hub yet.
+func missingDeps(hub *defaultHub, p Plugin) []*InjectionError {
+	errs := hub.missing(p)
+	if d, ok := p.(dependent); ok {
+		for _, c := range d.dependsOn() {
+			errs = append(errs, hub.missing(c)...)
+		}
+	}
+
+	if d, ok := p.(Describer); ok {
+		for _, name := range d.Describe().Requires {
+			if _, found := hub.lookup(name); !found {
+				errs = append(errs, &InjectionError{
+					Type:       reflect.TypeOf(p),
+					Dependency: name,
+					Err:        errNotRegistered(name),
+				})
+			}
+		}
+	}
+
+	if o, ok := p.(overrider); ok {
+		if _, found := hub.lookup(o.overrides()); !found {
+			errs = append(errs, &InjectionError{
+				Type:       reflect.TypeOf(p),
+				Dependency: o.overrides(),
+				Err:        errNotRegistered(o.overrides()),
+			})
+		}
+	}
+
+	return errs
+}
+
+// nextReady returns the index of the first plugin whose dependencies are all registered.
+// A plugin also waits for components it injects via optional tags if other pending plugins
+// are going to register them.
+// Plugins overriding components take priority so consumers get overridden components.
+// Conditional plugins wait for other ready plugins so their conditions see components
+// registered by them, while plugins injecting all matching components via "*,all"
+// wait for the rest as they may register more matching components.
+// It returns -1 if there is no such plugin.
+func nextReady(hub *defaultHub, plugins []Plugin) int {
+	priorities := []func(p Plugin) bool{
+		func(p Plugin) bool {
+			_, ok := p.(overrider)
+			return ok
+		},
+		func(p Plugin) bool { return !isCollector(p) && !isConditional(p) },
+		func(p Plugin) bool { return !isCollector(p) },
+		func(p Plugin) bool { return true },
+	}
+
+	providers := declaredProviders(plugins)
+	for _, matches := range priorities {
+		for i, p := range plugins {
+			if matches(p) && len(missingDeps(hub, p)) == 0 && len(pendingOptionalDeps(hub, p, i, providers)) == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// declaredProviders maps names of components to the index of the plugin declaring to register them.
+func declaredProviders(plugins []Plugin) map[string]int {
+	providers := map[string]int{}
+	for i, p := range plugins {
+		for _, name := range describe(p).Provides {
+			providers[name] = i
+		}
+	}
+
+	return providers
+}
+
+// pendingOptionalDeps returns indexes of other plugins registering components
+// that the plugin at idx injects via optional tags.
+func pendingOptionalDeps(hub *defaultHub, p Plugin, idx int, providers map[string]int) []int {
+	components := []interface{}{p}
+	if d, ok := p.(dependent); ok {
+		components = append(components, d.dependsOn()...)
+	}
+
+	var deps []int
+	for _, c := range components {
+		for _, name := range optionalNames(c) {
+			if _, found := hub.lookup(name); found {
+				continue
+			}
+
+			if j, found := providers[name]; found && j != idx {
+				deps = append(deps, j)
+			}
+		}
+	}
+
+	return deps
+}
+
+// isConditional reports whether a plugin is created via When.
+func isConditional(p Plugin) bool {
+	_, ok := p.(*conditionalPlugin)
+	return ok
+}
+
+// isCollector reports whether a plugin injects all matching components via "*,all".
+func isCollector(p Plugin) bool {
+	if collectsAll(p) {
+		return true
+	}
+
+	if d, ok := p.(dependent); ok {
+		for _, c := range d.dependsOn() {
+			if collectsAll(c) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// newDependencyReport creates a report of all unresolved dependencies of pending plugins.
+func newDependencyReport(hub *defaultHub, plugins []Plugin) *DependencyReport {
+	report := &DependencyReport{
+		Cycle: findCycle(hub, plugins),
+	}
+
+	for _, p := range plugins {
+		for _, err := range missingDeps(hub, p) {
+			err.Chain = []string{pluginName(p)}
+			report.Errors = append(report.Errors, err)
+		}
+	}
+
+	return report
+}
+
+// findCycle looks for a dependency cycle among plugins that can't be initialized.
+// It returns the chain of plugin names forming the cycle or nil if there is no cycle.
+// Plugins which don't declare what they register are assumed to register components
+// that no other plugin declares, so cycles among them are detected as well.
+func findCycle(hub *defaultHub, plugins []Plugin) []string {
+	providers := declaredProviders(plugins)
+	edges := make([][]int, len(plugins))
+	undeclared := make([][]string, len(plugins))
+	for i, p := range plugins {
+		edges[i] = pendingOptionalDeps(hub, p, i, providers)
+		for _, err := range missingDeps(hub, p) {
+			if j, found := providers[err.Dependency]; found {
+				edges[i] = append(edges[i], j)
+				continue
+			}
+
+			undeclared[i] = append(undeclared[i], err.Dependency)
+		}
+	}
+
+	if cycle := cycleOf(edges); cycle != nil {
+		return pluginNames(plugins, cycle)
+	}
+
+	for i, names := range undeclared {
+		for j, p := range plugins {
+			if i != j && len(names) > 0 && !declares(p) {
+				edges[i] = append(edges[i], j)
+			}
+		}
+	}
+
+	if cycle := cycleOf(edges); cycle != nil {
+		return pluginNames(plugins, cycle)
+	}
+
+	return nil
+}
+
+// declares reports whether a plugin declares what it registers.
+func declares(p Plugin) bool {
+	switch p.(type) {
+	case provider, Describer:
+		return true
+	default:
+		return false
+	}
+}
+
+// cycleOf returns the chain of indexes forming a cycle in the graph or nil if there is no cycle.
+func cycleOf(edges [][]int) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	states := make([]int, len(edges))
+	var stack []int
+	var visit func(i int) []int
+	visit = func(i int) []int {
+		states[i] = visiting
+		stack = append(stack, i)
+		for _, j := range edges[i] {
+			switch states[j] {
+			case visiting:
+				for k := range stack {
+					if stack[k] == j {
+						return append(append([]int{}, stack[k:]...), j)
+					}
+				}
+			case unvisited:
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		states[i] = visited
+		return nil
+	}
+
+	for i := range edges {
+		if states[i] != unvisited {
+			continue
+		}
+
+		if cycle := visit(i); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+// pluginNames returns names of plugins at the given indexes.
+func pluginNames(plugins []Plugin, indexes []int) []string {
+	names := make([]string, len(indexes))
+	for k, idx := range indexes {
+		names[k] = pluginName(plugins[idx])
+	}
+
+	return names
+}
+
+// pluginName returns a readable name of a plugin for diagnostics.
+func pluginName(p Plugin) string {
+	if d, ok := p.(Describer); ok {
+		if name := d.Describe().Name; name != "" {
+			return name
+		}
+	}
+
+	if m, ok := p.(*ModulePlugin); ok {
+		return "module " + m.name
+	}
+
+	if o, ok := p.(overrider); ok {
+		return "override of " + o.overrides()
+	}
+
+	if pr, ok := p.(provider); ok {
+		if names := pr.provides(); len(names) > 0 {
+			return strings.Join(names, ",")
+		}
+	}
+
+	return fmt.Sprintf("%T", p)
+}
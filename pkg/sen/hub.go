@@ -1,40 +1,79 @@
 package sen
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 )
 
 const (
 	autoInjectionTag = "*"
 	optionalTag      = "optional"
+	allTag           = "all"
+	qualifierTag     = "qualifier="
 	injectTag        = "inject"
 )
 
-// ErrComponentNotRegistered is returned when the expected component isn't registered
-// so it couldn't be found by name.
-var ErrComponentNotRegistered = errors.New("sen: the component is not registered")
-
 // Hub is a container of components.
 // It allows registering new components by names as well as
 // injecting dependencies into a component via tags or types.
+//
+// Supported inject tags are:
+//   - `inject:"name"` injects the component registered under the name.
+//   - `inject:"*"` injects the only component that is assignable to the field type.
+//   - `inject:"*,all"` injects all components that are assignable to the element type
+//     of a slice or a map keyed by component names, in the registration order.
+//   - `inject:"*,qualifier=name"` only considers components registered with the qualifier via WithQualifier.
+//   - `inject:"name,optional"` or `inject:"*,optional"` skips the field if there is no suitable component.
+//
+// If multiple components are suitable for a type-based injection, the one registered
+// with AsPrimary is chosen.
+//
+// A Hub is safe for concurrent use by multiple goroutines.
 type Hub interface {
 	// Register injects dependencies into a component and register the component into the depdenency container
 	// for the next injection.
-	Register(name string, component interface{}) error
+	// Options like AsPrimary or WithQualifier can be used to help type-based injection.
+	Register(name string, component interface{}, opts ...RegisterOption) error
+
+	// RegisterConstructor registers a constructor of a component under the given name.
+	// The constructor must be a function that returns the component and optionally an error,
+	// e.g. func(deps...) (T, error). Its arguments are resolved from the hub by types.
+	// The constructor is invoked when the component is injected or retrieved and
+	// the result is reused according to its lifetime, which is Singleton by default.
+	RegisterConstructor(name string, constructor interface{}, opts ...RegisterOption) error
 
 	// Retrieve retrieves a component via name. It returns an error if there is any.
 	Retrieve(name string) (interface{}, error)
 
 	// Inject injects dependencies into a component.
 	Inject(component interface{}) error
+
+	// NewScope creates a child hub. Components are resolved from the scope first
+	// and then from its parent. Components registered into the scope aren't visible to the parent.
+	NewScope() Scope
 }
 
-func newHub() Hub {
+// Scope is a child Hub that is used for components with a shorter life,
+// e.g. per-request or per-job components.
+type Scope interface {
+	Hub
+
+	// OnDispose adds a hook to be executed when the scope is disposed.
+	OnDispose(h Hook)
+
+	// Dispose disposes the scope by executing all OnDispose hooks in the reverse order.
+	// All hooks are executed and the first error is returned.
+	Dispose(ctx context.Context) error
+}
+
+func newHub() *defaultHub {
 	hub := &defaultHub{
-		dependencies: make(map[string]*dependency),
+		dependencies:    make(map[string]*dependency),
+		scopedInstances: make(map[*dependency]reflect.Value),
 	}
 
 	_ = hub.Register("hub", hub)
@@ -42,42 +81,161 @@ func newHub() Hub {
 }
 
 type dependency struct {
+	name         string
+	owner        *defaultHub
 	value        interface{}
 	reflectValue reflect.Value
 	reflectType  reflect.Type
+
+	// constructor is set if the component is lazily constructed.
+	constructor reflect.Value
+	lifetime    Lifetime
+	primary     bool
+	qualifier   string
+	constructed bool
+
+	// target is set if the dependency is an alias of another one,
+	// e.g. a component exported from a module.
+	target *dependency
+
+	// decorators wrap the component once it's constructed, see Decorate.
+	decorators []decorator
+
+	// mu guards the construction of singleton components.
+	mu sync.Mutex
+}
+
+// assignableTo reports whether the component can be injected into the given type.
+func (dep *dependency) assignableTo(t reflect.Type) bool {
+	return dep.reflectType != nil && dep.reflectType.AssignableTo(t)
+}
+
+// matches reports whether the component can be injected into the given type
+// and it has the given qualifier if required.
+func (dep *dependency) matches(t reflect.Type, qualifier string) bool {
+	return dep.assignableTo(t) && (qualifier == "" || dep.qualifier == qualifier)
 }
 
 type defaultHub struct {
-	dependencies map[string]*dependency
+	mu              sync.RWMutex
+	parent          *defaultHub
+	dependencies    map[string]*dependency
+	names           []string
+	instances       []*dependency
+	scopedInstances map[*dependency]reflect.Value
+	scopedLocks     map[*dependency]*sync.Mutex
+	disposeHooks    []Hook
+	sealed          bool
+	// scope is set if the hub is created via NewScope.
+	scope bool
+
+	// prefix is the namespace of components in a module hub, e.g. "payments.".
+	prefix string
+	// tracker keeps track of instances for the hub. Module hubs share the tracker
+	// of their parents so instances are ordered across modules.
+	tracker *defaultHub
+}
+
+// resolution keeps track of lazy dependencies being constructed
+// in a single resolution to detect circular dependencies.
+type resolution struct {
+	path []*dependency
+}
+
+func (res *resolution) enter(dep *dependency) error {
+	for _, d := range res.path {
+		if d == dep {
+			return fmt.Errorf("hub: circular dependency detected while constructing %s", dep.name)
+		}
+	}
+
+	res.path = append(res.path, dep)
+	return nil
+}
+
+func (res *resolution) leave() {
+	res.path = res.path[:len(res.path)-1]
 }
 
-func (hub *defaultHub) Register(name string, component interface{}) error {
+// singleton returns the singleton being constructed in the resolution if there is one.
+func (res *resolution) singleton() *dependency {
+	for _, d := range res.path {
+		if d.lifetime == Singleton {
+			return d
+		}
+	}
+
+	return nil
+}
+
+func (hub *defaultHub) Register(name string, component interface{}, opts ...RegisterOption) error {
 	if err := hub.validateNamne(name); err != nil {
 		return err
 	}
 
+	options := newRegisterOptions(opts)
+	if options.lifetime != Singleton {
+		return fmt.Errorf("hub: %s can't be %s, only components with constructors can", name, options.lifetime)
+	}
+
 	toAddDep := &dependency{
+		name:         name,
+		owner:        hub,
 		value:        component,
 		reflectType:  reflect.TypeOf(component),
 		reflectValue: reflect.ValueOf(component),
+		constructed:  true,
+		primary:      options.primary,
+		qualifier:    options.qualifier,
 	}
 
-	if err := hub.inject(toAddDep); err != nil {
+	if err := hub.inject(toAddDep, &resolution{}); err != nil {
 		return err
 	}
 
-	hub.dependencies[name] = toAddDep
+	return hub.add(toAddDep)
+}
 
-	return nil
+func (hub *defaultHub) RegisterConstructor(name string, constructor interface{}, opts ...RegisterOption) error {
+	if err := hub.validateNamne(name); err != nil {
+		return err
+	}
+
+	if err := validateConstructor(constructor); err != nil {
+		return err
+	}
+
+	options := newRegisterOptions(opts)
+	constructorValue := reflect.ValueOf(constructor)
+
+	return hub.add(&dependency{
+		name:        name,
+		owner:       hub,
+		reflectType: constructorValue.Type().Out(0),
+		constructor: constructorValue,
+		lifetime:    options.lifetime,
+		primary:     options.primary,
+		qualifier:   options.qualifier,
+	})
 }
 
 func (hub *defaultHub) Retrieve(name string) (interface{}, error) {
-	loadedDep, found := hub.dependencies[name]
+	loadedDep, found := hub.lookup(name)
 	if !found {
 		return nil, ErrComponentNotRegistered
 	}
 
-	return loadedDep.value, nil
+	v, err := hub.instance(loadedDep, &resolution{})
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.IsValid() {
+		// the component is registered as nil.
+		return nil, nil
+	}
+
+	return v.Interface(), nil
 }
 
 func (hub *defaultHub) Inject(component interface{}) error {
@@ -87,10 +245,179 @@ func (hub *defaultHub) Inject(component interface{}) error {
 		reflectValue: reflect.ValueOf(component),
 	}
 
-	return hub.inject(toAddDep)
+	return hub.inject(toAddDep, &resolution{})
+}
+
+func (hub *defaultHub) NewScope() Scope {
+	scope := &defaultHub{
+		parent:          hub,
+		dependencies:    make(map[string]*dependency),
+		scopedInstances: make(map[*dependency]reflect.Value),
+		scope:           true,
+	}
+
+	_ = scope.Register("hub", scope)
+	return scope
+}
+
+func (hub *defaultHub) OnDispose(h Hook) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.disposeHooks = append(hub.disposeHooks, h)
+}
+
+func (hub *defaultHub) Dispose(ctx context.Context) error {
+	hub.mu.RLock()
+	hooks := hub.disposeHooks
+	hub.mu.RUnlock()
+
+	var err error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hookErr := hooks[i](ctx); hookErr != nil && err == nil {
+			err = hookErr
+		}
+	}
+
+	return err
+}
+
+// seal prevents new components from being registered into the hub.
+func (hub *defaultHub) seal() {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.sealed = true
+}
+
+// add adds a dependency into the hub and keeps track of the registration order.
+func (hub *defaultHub) add(dep *dependency) error {
+	if err := hub.insert(dep); err != nil {
+		return err
+	}
+
+	if !dep.constructor.IsValid() && dep.target == nil {
+		hub.addInstance(dep)
+	}
+
+	return nil
+}
+
+// insert inserts a dependency into the hub if its name is still valid.
+func (hub *defaultHub) insert(dep *dependency) error {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	// the name is validated again as the hub might be changed while the dependency is being injected.
+	if err := hub.validateNameLocked(dep.name); err != nil {
+		return err
+	}
+
+	hub.dependencies[dep.name] = dep
+	hub.names = append(hub.names, dep.name)
+	return nil
+}
+
+// addInstance keeps track of the order that components are instantiated.
+func (hub *defaultHub) addInstance(dep *dependency) {
+	if hub.tracker != nil {
+		hub.tracker.addInstance(dep)
+		return
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.instances = append(hub.instances, dep)
+}
+
+// newModule creates a child hub whose components are namespaced by name.
+// Components of the module are only visible to the parent if they are exported.
+func (hub *defaultHub) newModule(name string) *defaultHub {
+	tracker := hub
+	if hub.tracker != nil {
+		tracker = hub.tracker
+	}
+
+	module := &defaultHub{
+		parent:          hub,
+		dependencies:    make(map[string]*dependency),
+		scopedInstances: make(map[*dependency]reflect.Value),
+		prefix:          hub.prefix + name + ".",
+		tracker:         tracker,
+	}
+
+	_ = module.Register("hub", module)
+	return module
+}
+
+// export registers an alias of a component of the hub into its parent under the given name.
+// The component is still constructed and injected by the hub.
+func (hub *defaultHub) export(name, as string) error {
+	hub.mu.RLock()
+	dep, found := hub.dependencies[name]
+	hub.mu.RUnlock()
+	if !found {
+		return errNotRegistered(name)
+	}
+
+	if err := hub.parent.validateNamne(as); err != nil {
+		return err
+	}
+
+	return hub.parent.add(&dependency{
+		name:        as,
+		owner:       hub.parent,
+		reflectType: dep.reflectType,
+		lifetime:    dep.lifetime,
+		qualifier:   dep.qualifier,
+		target:      dep,
+	})
+}
+
+// instantiated returns singleton components in the order they are instantiated.
+// Since dependencies are always instantiated first, it's also the order of dependencies.
+func (hub *defaultHub) instantiated() []*dependency {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	return append([]*dependency(nil), hub.instances...)
+}
+
+// ordered returns dependencies registered into the hub in the registration order.
+func (hub *defaultHub) ordered() []*dependency {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	deps := make([]*dependency, len(hub.names))
+	for i, name := range hub.names {
+		deps[i] = hub.dependencies[name]
+	}
+
+	return deps
+}
+
+// lookup finds a dependency by name from the hub and then its parents.
+func (hub *defaultHub) lookup(name string) (*dependency, bool) {
+	for h := hub; h != nil; h = h.parent {
+		h.mu.RLock()
+		dep, found := h.dependencies[name]
+		h.mu.RUnlock()
+		if found {
+			return dep, true
+		}
+	}
+
+	return nil, false
 }
 
 func (hub *defaultHub) validateNamne(name string) error {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	return hub.validateNameLocked(name)
+}
+
+func (hub *defaultHub) validateNameLocked(name string) error {
+	if hub.sealed {
+		return fmt.Errorf("hub: unable to register %s: %w", name, ErrHubSealed)
+	}
+
 	if _, found := hub.dependencies[name]; found {
 		return fmt.Errorf("hub: %s is already registered", name)
 	}
@@ -102,7 +429,11 @@ func (hub *defaultHub) validateNamne(name string) error {
 	return nil
 }
 
-func (hub *defaultHub) inject(dep *dependency) error {
+func (hub *defaultHub) inject(dep *dependency, res *resolution) error {
+	if dep.reflectType == nil {
+		return nil
+	}
+
 	if !isStructPtr(dep.reflectType) {
 		if hasInjectTag(dep) {
 			return fmt.Errorf("hub: %s is not injectable, a pointer is expected", dep.reflectType)
@@ -113,7 +444,6 @@ func (hub *defaultHub) inject(dep *dependency) error {
 
 	for i := 0; i < dep.reflectValue.Elem().NumField(); i++ {
 		fieldValue := dep.reflectValue.Elem().Field(i)
-		fieldType := fieldValue.Type()
 		structField := dep.reflectType.Elem().Field(i)
 		fieldTag := structField.Tag
 		tagValue, ok := fieldTag.Lookup(injectTag)
@@ -121,78 +451,528 @@ func (hub *defaultHub) inject(dep *dependency) error {
 			continue
 		}
 
-		loadedDep, err := hub.loadDepForTag(tagValue, fieldType)
-		if err != nil {
-			return err
+		if err := hub.injectField(fieldValue, tagValue, res); err != nil {
+			return newInjectionError(dep.reflectType, structField, err)
 		}
+	}
 
-		if loadedDep == nil {
-			// this is an optional field and there is no suitable dependency to inject.
-			continue
+	return nil
+}
+
+func (hub *defaultHub) injectField(fieldValue reflect.Value, tagValue string, res *resolution) error {
+	fieldType := fieldValue.Type()
+	tag, err := parseTag(tagValue)
+	if err != nil {
+		return err
+	}
+
+	if tag.all {
+		return hub.injectAll(fieldValue, tag.qualifier, res)
+	}
+
+	loadedDep, err := hub.loadDepForTag(tag, fieldType)
+	if err != nil {
+		return err
+	}
+
+	if loadedDep == nil {
+		// this is an optional field and there is no suitable dependency to inject.
+		return nil
+	}
+
+	if !loadedDep.reflectType.AssignableTo(fieldType) {
+		return fmt.Errorf("hub: %s is not assignable from %s", fieldType, loadedDep.reflectType)
+	}
+
+	v, err := hub.instance(loadedDep, res)
+	if err != nil {
+		return err
+	}
+
+	fieldValue.Set(v)
+	return nil
+}
+
+// instance returns the instance of a dependency.
+// Lazy dependencies are constructed according to their lifetimes.
+func (hub *defaultHub) instance(dep *dependency, res *resolution) (reflect.Value, error) {
+	if dep.target != nil {
+		return hub.instance(dep.target, res)
+	}
+
+	if !dep.constructor.IsValid() {
+		return dep.reflectValue, nil
+	}
+
+	if dep.lifetime == Scoped {
+		// a singleton would capture the scoped component and share it across all scopes.
+		if singleton := res.singleton(); singleton != nil {
+			return reflect.Value{}, fmt.Errorf("hub: singleton %s can't depend on scoped %s", singleton.name, dep.name)
 		}
 
-		if !loadedDep.reflectType.AssignableTo(fieldType) {
-			return fmt.Errorf("hub: %s is not assignable from %s", fieldType, loadedDep.reflectType)
+		// otherwise the component would be shared by the whole application.
+		if !hub.scope {
+			return reflect.Value{}, fmt.Errorf("hub: scoped %s can only be resolved from a scope", dep.name)
 		}
+	}
 
-		fieldValue.Set(loadedDep.reflectValue)
+	if err := res.enter(dep); err != nil {
+		return reflect.Value{}, err
 	}
+	defer res.leave()
 
-	return nil
+	if dep.lifetime == Transient {
+		return hub.buildVisible(dep, res)
+	}
+
+	if dep.lifetime == Scoped {
+		return hub.scopedInstance(dep, res)
+	}
+
+	dep.mu.Lock()
+	defer dep.mu.Unlock()
+
+	if dep.constructed {
+		return dep.reflectValue, nil
+	}
+
+	// dependencies of a singleton are resolved from the hub it's registered to.
+	v, err := dep.owner.build(dep, res)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	dep.value = v.Interface()
+	dep.reflectValue = v
+	dep.constructed = true
+	dep.owner.addInstance(dep)
+	return v, nil
 }
 
-func (hub *defaultHub) loadDepForTag(tag string, t reflect.Type) (*dependency, error) {
-	tagName, optional, err := parseTag(tag)
+// scopedInstance returns the instance of a scoped dependency in the hub, constructing it if needed.
+// The construction is only serialized within the hub so scopes don't block each other.
+func (hub *defaultHub) scopedInstance(dep *dependency, res *resolution) (reflect.Value, error) {
+	mu := hub.scopedLock(dep)
+	mu.Lock()
+	defer mu.Unlock()
+
+	hub.mu.RLock()
+	v, found := hub.scopedInstances[dep]
+	hub.mu.RUnlock()
+	if found {
+		return v, nil
+	}
+
+	v, err := hub.buildVisible(dep, res)
 	if err != nil {
-		return nil, err
+		return reflect.Value{}, err
 	}
 
-	if tag == autoInjectionTag {
-		return hub.findByType(t, optional)
+	hub.mu.Lock()
+	hub.scopedInstances[dep] = v
+	hub.mu.Unlock()
+	return v, nil
+}
+
+// scopedLock returns the mutex guarding the construction of a scoped dependency in the hub.
+func (hub *defaultHub) scopedLock(dep *dependency) *sync.Mutex {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if hub.scopedLocks == nil {
+		hub.scopedLocks = make(map[*dependency]*sync.Mutex)
 	}
 
-	loadedDep, found := hub.dependencies[tagName]
-	if !found && !optional {
-		return nil, fmt.Errorf("hub: %s is not registered", tagName)
+	mu, found := hub.scopedLocks[dep]
+	if !found {
+		mu = &sync.Mutex{}
+		hub.scopedLocks[dep] = mu
+	}
+
+	return mu
+}
+
+// buildVisible builds a transient or scoped dependency requested from the hub.
+// If the hub can't see the hub the dependency is registered to, e.g. the dependency is
+// exported from a module, arguments of its constructor are resolved from that hub first
+// and then from the requesting hub, so private components of the module and components
+// of the scope can both be used.
+func (hub *defaultHub) buildVisible(dep *dependency, res *resolution) (reflect.Value, error) {
+	if hub.sees(dep.owner) {
+		return hub.build(dep, res)
+	}
+
+	return hub.buildFrom(dep, res, dep.owner, hub)
+}
+
+// sees returns whether components of the other hub are visible to the hub,
+// i.e. the other hub is the hub itself or one of its parents.
+func (hub *defaultHub) sees(other *defaultHub) bool {
+	for h := hub; h != nil; h = h.parent {
+		if h == other {
+			return true
+		}
+	}
+
+	return false
+}
+
+// build invokes the constructor of a lazy dependency. Arguments of the constructor
+// are resolved from the hub by types.
+func (hub *defaultHub) build(dep *dependency, res *resolution) (reflect.Value, error) {
+	return hub.buildFrom(dep, res, hub)
+}
+
+// buildFrom invokes the constructor of a lazy dependency with arguments resolved from
+// the first of hubs having them. Arguments are instantiated by the hub so scoped ones
+// belong to it, while the component is injected from the first hub.
+func (hub *defaultHub) buildFrom(dep *dependency, res *resolution, hubs ...*defaultHub) (reflect.Value, error) {
+	constructorType := dep.constructor.Type()
+	args := make([]reflect.Value, constructorType.NumIn())
+	for i := range args {
+		var argDep *dependency
+		var err error
+		for _, h := range hubs {
+			argDep, err = h.findByType(constructorType.In(i), "", false)
+			if !errors.Is(err, ErrComponentNotRegistered) {
+				break
+			}
+		}
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		args[i], err = hub.instance(argDep, res)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	results := dep.constructor.Call(args)
+	if len(results) == 2 && !results[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("hub: unable to construct %s: %w", dep.name, results[1].Interface().(error))
+	}
+
+	component := results[0].Interface()
+	if err := hubs[0].inject(&dependency{
+		value:        component,
+		reflectType:  reflect.TypeOf(component),
+		reflectValue: reflect.ValueOf(component),
+	}, res); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return applyDecorators(dep, results[0])
+}
+
+// injectAll injects all components that are assignable to the element type of
+// a slice or a map keyed by component names.
+func (hub *defaultHub) injectAll(fieldValue reflect.Value, qualifier string, res *resolution) error {
+	fieldType := fieldValue.Type()
+	isSlice := fieldType.Kind() == reflect.Slice
+	isMap := fieldType.Kind() == reflect.Map && fieldType.Key().Kind() == reflect.String
+	if !isSlice && !isMap {
+		return fmt.Errorf("hub: %s is not injectable with %s, a slice or a map keyed by string is expected", fieldType, allTag)
+	}
+
+	deps := hub.findAllByType(fieldType.Elem(), qualifier)
+	if isSlice {
+		values := reflect.MakeSlice(fieldType, 0, len(deps))
+		for _, dep := range deps {
+			v, err := hub.instance(dep, res)
+			if err != nil {
+				return err
+			}
+
+			values = reflect.Append(values, v)
+		}
+
+		fieldValue.Set(values)
+		return nil
+	}
+
+	values := reflect.MakeMapWithSize(fieldType, len(deps))
+	for _, dep := range deps {
+		v, err := hub.instance(dep, res)
+		if err != nil {
+			return err
+		}
+
+		values.SetMapIndex(reflect.ValueOf(dep.name).Convert(fieldType.Key()), v)
+	}
+
+	fieldValue.Set(values)
+	return nil
+}
+
+func (hub *defaultHub) loadDepForTag(tag *tagOptions, t reflect.Type) (*dependency, error) {
+	if tag.name == autoInjectionTag {
+		return hub.findByType(t, tag.qualifier, tag.optional)
+	}
+
+	loadedDep, found := hub.lookup(tag.name)
+	if !found && !tag.optional {
+		return nil, errNotRegistered(tag.name)
 	}
 
 	return loadedDep, nil
 }
 
-func (hub *defaultHub) findByType(t reflect.Type, optional bool) (*dependency, error) {
-	var foundVal *dependency
-	for _, v := range hub.dependencies {
-		if v.reflectType.AssignableTo(t) {
-			if foundVal != nil {
-				return nil, fmt.Errorf("hub: there is a conflict when finding the dependency for %s", t.String())
+// findByType finds the dependency which is assignable to the given type and has the given qualifier.
+// Dependencies in the hub take precedence over ones in its parents.
+// If there are multiple candidates, the one registered as primary is chosen.
+func (hub *defaultHub) findByType(t reflect.Type, qualifier string, optional bool) (*dependency, error) {
+	var candidates []*dependency
+	for h := hub; h != nil && len(candidates) == 0; h = h.parent {
+		for _, v := range h.ordered() {
+			if v.matches(t, qualifier) {
+				candidates = append(candidates, v)
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		if optional {
+			return nil, nil
+		}
+
+		return nil, errNotFoundByType(t)
+	case 1:
+		return candidates[0], nil
+	}
+
+	var primary *dependency
+	names := make([]string, len(candidates))
+	for i, v := range candidates {
+		names[i] = v.name
+		if !v.primary {
+			continue
+		}
+
+		if primary != nil {
+			return nil, fmt.Errorf("hub: there are multiple primary dependencies for %s: %s, %s", t, primary.name, v.name)
+		}
+
+		primary = v
+	}
+
+	if primary == nil {
+		return nil, fmt.Errorf("hub: there is a conflict when finding the dependency for %s: %s", t, strings.Join(names, ", "))
+	}
+
+	return primary, nil
+}
+
+// findAllByType finds all dependencies which are assignable to the given type in the registration order.
+// Dependencies registered into parents come first. A dependency in the hub replaces
+// the one with the same name in its parents.
+func (hub *defaultHub) findAllByType(t reflect.Type, qualifier string) []*dependency {
+	var hubs []*defaultHub
+	for h := hub; h != nil; h = h.parent {
+		hubs = append([]*defaultHub{h}, hubs...)
+	}
+
+	var deps []*dependency
+	indexes := map[string]int{}
+	for _, h := range hubs {
+		for _, v := range h.ordered() {
+			if !v.matches(t, qualifier) {
+				continue
+			}
+
+			if idx, found := indexes[v.name]; found {
+				deps[idx] = v
+				continue
+			}
+
+			indexes[v.name] = len(deps)
+			deps = append(deps, v)
+		}
+	}
+
+	return deps
+}
+
+// missing returns errors for dependencies which are required by the component
+// but aren't registered yet.
+func (hub *defaultHub) missing(component interface{}) []*InjectionError {
+	t := reflect.TypeOf(component)
+	if t == nil || !isStructPtr(t) {
+		return nil
+	}
+
+	var errs []*InjectionError
+	for i := 0; i < t.Elem().NumField(); i++ {
+		structField := t.Elem().Field(i)
+		tagValue, ok := structField.Tag.Lookup(injectTag)
+		if !ok {
+			continue
+		}
+
+		tag, err := parseTag(tagValue)
+		if err != nil || tag.optional || tag.all {
+			continue
+		}
+
+		if tag.name == autoInjectionTag {
+			if !hub.hasType(structField.Type, tag.qualifier) {
+				errs = append(errs, newInjectionError(t, structField, errNotFoundByType(structField.Type)))
 			}
+			continue
+		}
+
+		if _, found := hub.lookup(tag.name); !found {
+			errs = append(errs, newInjectionError(t, structField, errNotRegistered(tag.name)))
+		}
+	}
+
+	return errs
+}
+
+// collectsAll reports whether the component has fields injected via "*,all".
+func collectsAll(component interface{}) bool {
+	t := reflect.TypeOf(component)
+	if t == nil || !isStructPtr(t) {
+		return false
+	}
+
+	for i := 0; i < t.Elem().NumField(); i++ {
+		tagValue, ok := t.Elem().Field(i).Tag.Lookup(injectTag)
+		if !ok {
+			continue
+		}
+
+		if tag, err := parseTag(tagValue); err == nil && tag.all {
+			return true
+		}
+	}
+
+	return false
+}
+
+// optionalNames returns names of components which are injected into the component via optional tags.
+func optionalNames(component interface{}) []string {
+	t := reflect.TypeOf(component)
+	if t == nil || !isStructPtr(t) {
+		return nil
+	}
 
-			foundVal = v
+	var names []string
+	for i := 0; i < t.Elem().NumField(); i++ {
+		tagValue, ok := t.Elem().Field(i).Tag.Lookup(injectTag)
+		if !ok {
+			continue
+		}
+
+		if tag, err := parseTag(tagValue); err == nil && tag.optional && tag.name != autoInjectionTag {
+			names = append(names, tag.name)
 		}
 	}
 
-	if foundVal == nil && !optional {
-		return nil, fmt.Errorf("hub: couldn't find the dependency for %s", t.String())
+	return names
+}
+
+func (hub *defaultHub) hasType(t reflect.Type, qualifier string) bool {
+	for h := hub; h != nil; h = h.parent {
+		for _, v := range h.ordered() {
+			if v.matches(t, qualifier) {
+				return true
+			}
+		}
 	}
 
-	return foundVal, nil
+	return false
+}
+
+// tagOptions represents a parsed inject tag, e.g. "name,optional" or "*,all".
+type tagOptions struct {
+	name      string
+	optional  bool
+	all       bool
+	qualifier string
 }
 
-func parseTag(tag string) (string, bool, error) {
+func parseTag(tag string) (*tagOptions, error) {
 	parts := strings.Split(tag, ",")
-	switch len(parts) {
-	case 2:
-		if parts[1] != optionalTag {
-			return "", false, fmt.Errorf("hub: %s is unexpected", parts[1])
+	if parts[0] == "" {
+		return nil, fmt.Errorf("hub: tag must not be empty")
+	}
+
+	opts := &tagOptions{
+		name: parts[0],
+	}
+
+	for _, part := range parts[1:] {
+		switch {
+		case part == optionalTag:
+			opts.optional = true
+		case part == allTag:
+			opts.all = true
+		case strings.HasPrefix(part, qualifierTag) && len(part) > len(qualifierTag):
+			opts.qualifier = strings.TrimPrefix(part, qualifierTag)
+		default:
+			return nil, fmt.Errorf("hub: %s is unexpected", part)
+		}
+	}
+
+	if opts.name != autoInjectionTag {
+		if opts.all {
+			return nil, fmt.Errorf("hub: %s is only supported with %s", allTag, autoInjectionTag)
 		}
 
-		return parts[0], parts[1] == optionalTag, nil
-	case 1:
-		return parts[0], false, nil
-	case 0:
-		return "", false, fmt.Errorf("hub: tag must not be empty")
+		if opts.qualifier != "" {
+			return nil, fmt.Errorf("hub: %s is only supported with %s", strings.TrimSuffix(qualifierTag, "="), autoInjectionTag)
+		}
+	}
+
+	return opts, nil
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func validateConstructor(constructor interface{}) error {
+	constructorType := reflect.TypeOf(constructor)
+	if constructorType == nil || constructorType.Kind() != reflect.Func {
+		return fmt.Errorf("hub: %s is not a constructor, a function is expected", typeString(constructorType))
+	}
+
+	switch {
+	case constructorType.NumOut() == 1:
+		return nil
+	case constructorType.NumOut() == 2 && constructorType.Out(1) == errorType:
+		return nil
 	default:
-		return "", false, fmt.Errorf("hub: unable to parse tag %s", tag)
+		return fmt.Errorf("hub: %s is not a constructor, it must return a component and optionally an error", constructorType)
+	}
+}
+
+func errNotRegistered(name string) error {
+	return &notRegisteredError{
+		msg: fmt.Sprintf("hub: %s is not registered", name),
+	}
+}
+
+func errNotFoundByType(t reflect.Type) error {
+	return &notRegisteredError{
+		msg: fmt.Sprintf("hub: couldn't find the dependency for %s", t),
+	}
+}
+
+func newInjectionError(t reflect.Type, field reflect.StructField, err error) *InjectionError {
+	tagValue := field.Tag.Get(injectTag)
+	dependency := strings.Split(tagValue, ",")[0]
+	if dependency == autoInjectionTag {
+		dependency = field.Type.String()
+	}
+
+	return &InjectionError{
+		Type:       t,
+		Field:      field.Name,
+		Tag:        tagValue,
+		Dependency: dependency,
+		Err:        err,
 	}
 }
 
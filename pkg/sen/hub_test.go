@@ -1,8 +1,14 @@
 package sen_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/bongnv/sen/pkg/sen"
 )
@@ -23,9 +29,22 @@ func TestHub(t *testing.T) {
 	t.Run("should return error if there is no registered dependency", func(t *testing.T) {
 		hub := sen.NewHub()
 		err := hub.Inject(&mockComponent{})
-		if fmt.Sprintf("%v", err) != "hub: data is not registered" {
+		if fmt.Sprintf("%v", err) != "hub: data is not registered (injecting *sen_test.mockComponent.Data)" {
 			t.Errorf("Unexpected err %v", err)
 		}
+
+		if !errors.Is(err, sen.ErrComponentNotRegistered) {
+			t.Errorf("Expected ErrComponentNotRegistered but got %v", err)
+		}
+
+		var injectionErr *sen.InjectionError
+		if !errors.As(err, &injectionErr) {
+			t.Fatalf("Expected InjectionError but got %v", err)
+		}
+
+		if injectionErr.Field != "Data" || injectionErr.Tag != "data" || injectionErr.Dependency != "data" {
+			t.Errorf("Unexpected InjectionError %+v", injectionErr)
+		}
 	})
 
 	t.Run("should not return if it's marked as optional", func(t *testing.T) {
@@ -39,7 +58,7 @@ func TestHub(t *testing.T) {
 	t.Run("should return an error for an unsupported option", func(t *testing.T) {
 		hub := sen.NewHub()
 		err := hub.Inject(&mockErrorComponent{})
-		if fmt.Sprintf("%v", err) != "hub: required is unexpected" {
+		if fmt.Sprintf("%v", err) != "hub: required is unexpected (injecting *sen_test.mockErrorComponent.Data)" {
 			t.Errorf("Unexpected error %v", err)
 		}
 	})
@@ -84,3 +103,465 @@ func TestHub_Retrieve(t *testing.T) {
 		}
 	})
 }
+
+type mockConfig struct {
+	Value int
+}
+
+func TestHub_RegisterConstructor(t *testing.T) {
+	t.Run("should construct the component lazily and only once", func(t *testing.T) {
+		hub := sen.NewHub()
+		called := 0
+		err := hub.RegisterConstructor("config", func() *mockConfig {
+			called++
+			return &mockConfig{Value: 10}
+		})
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if called != 0 {
+			t.Errorf("Expected the constructor isn't called but got %d", called)
+		}
+
+		first, err := hub.Retrieve("config")
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		second, err := hub.Retrieve("config")
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if called != 1 {
+			t.Errorf("Expected the constructor is called once but got %d", called)
+		}
+
+		if first != second {
+			t.Errorf("Expected the same instance but got %v and %v", first, second)
+		}
+	})
+
+	t.Run("should resolve arguments of the constructor from the hub", func(t *testing.T) {
+		hub := sen.NewHub()
+		_ = hub.Register("config", &mockConfig{Value: 10})
+		err := hub.RegisterConstructor("data", func(cfg *mockConfig) (int, error) {
+			return cfg.Value * 2, nil
+		})
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		component := &mockComponent{}
+		if err := hub.Inject(component); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if component.Data != 20 {
+			t.Errorf("Unexpected data %v", component.Data)
+		}
+	})
+
+	t.Run("should propagate the error from the constructor", func(t *testing.T) {
+		hub := sen.NewHub()
+		_ = hub.RegisterConstructor("data", func() (int, error) {
+			return 0, errors.New("random error")
+		})
+
+		err := hub.Inject(&mockComponent{})
+		if fmt.Sprintf("%v", err) != "hub: unable to construct data: random error (injecting *sen_test.mockComponent.Data)" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should return an error if there is a circular dependency", func(t *testing.T) {
+		hub := sen.NewHub()
+		_ = hub.RegisterConstructor("config", func(data int) *mockConfig {
+			return &mockConfig{Value: data}
+		})
+		_ = hub.RegisterConstructor("data", func(cfg *mockConfig) int {
+			return cfg.Value
+		})
+
+		_, err := hub.Retrieve("config")
+		if fmt.Sprintf("%v", err) != "hub: circular dependency detected while constructing config" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should return an error if it isn't a constructor", func(t *testing.T) {
+		hub := sen.NewHub()
+		err := hub.RegisterConstructor("data", func() (int, int) {
+			return 0, 0
+		})
+		if fmt.Sprintf("%v", err) != "hub: func() (int, int) is not a constructor, it must return a component and optionally an error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err = hub.RegisterConstructor("data", 10)
+		if fmt.Sprintf("%v", err) != "hub: int is not a constructor, a function is expected" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
+
+func TestHub_NewScope(t *testing.T) {
+	t.Run("should resolve components from the scope first and then its parent", func(t *testing.T) {
+		hub := sen.NewHub()
+		_ = hub.Register("data", 10)
+		_ = hub.Register("config", &mockConfig{Value: 1})
+
+		scope := hub.NewScope()
+		if err := scope.Register("data", 20); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		data, err := scope.Retrieve("data")
+		if err != nil || data != 20 {
+			t.Errorf("Unexpected data %v, err %v", data, err)
+		}
+
+		cfg, err := sen.Resolve[*mockConfig](scope)
+		if err != nil || cfg.Value != 1 {
+			t.Errorf("Unexpected config %v, err %v", cfg, err)
+		}
+
+		data, err = hub.Retrieve("data")
+		if err != nil || data != 10 {
+			t.Errorf("Unexpected data %v, err %v", data, err)
+		}
+	})
+
+	t.Run("should inject the scope as hub", func(t *testing.T) {
+		scope := sen.NewHub().NewScope()
+		loadedHub, err := scope.Retrieve("hub")
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if loadedHub != scope {
+			t.Errorf("Expected the scope but got %v", loadedHub)
+		}
+	})
+
+	t.Run("should execute dispose hooks in the reverse order", func(t *testing.T) {
+		var calls []int
+		scope := sen.NewHub().NewScope()
+		scope.OnDispose(func(_ context.Context) error {
+			calls = append(calls, 1)
+			return errors.New("dispose error")
+		})
+		scope.OnDispose(func(_ context.Context) error {
+			calls = append(calls, 2)
+			return nil
+		})
+
+		err := scope.Dispose(context.Background())
+		if fmt.Sprintf("%v", err) != "dispose error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if fmt.Sprint(calls) != "[2 1]" {
+			t.Errorf("Unexpected calls %v", calls)
+		}
+	})
+}
+
+func TestHub_Lifetime(t *testing.T) {
+	t.Run("should construct a transient component for every retrieval", func(t *testing.T) {
+		hub := sen.NewHub()
+		called := 0
+		err := hub.RegisterConstructor("config", func() *mockConfig {
+			called++
+			return &mockConfig{Value: called}
+		}, sen.WithLifetime(sen.Transient))
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		first, _ := sen.ResolveNamed[*mockConfig](hub, "config")
+		second, _ := sen.ResolveNamed[*mockConfig](hub, "config")
+		if first == second || first.Value != 1 || second.Value != 2 {
+			t.Errorf("Expected different instances but got %v and %v", first, second)
+		}
+	})
+
+	t.Run("should construct a scoped component once per scope", func(t *testing.T) {
+		hub := sen.NewHub()
+		err := hub.RegisterConstructor("config", func() *mockConfig {
+			return &mockConfig{}
+		}, sen.WithLifetime(sen.Scoped))
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		scope1 := hub.NewScope()
+		scope2 := hub.NewScope()
+		first, _ := sen.ResolveNamed[*mockConfig](scope1, "config")
+		second, _ := sen.ResolveNamed[*mockConfig](scope1, "config")
+		third, _ := sen.ResolveNamed[*mockConfig](scope2, "config")
+		if first != second {
+			t.Errorf("Expected the same instance in a scope but got %v and %v", first, second)
+		}
+
+		if first == third {
+			t.Errorf("Expected different instances in different scopes")
+		}
+	})
+
+	t.Run("should resolve dependencies of a scoped component from the scope", func(t *testing.T) {
+		hub := sen.NewHub()
+		_ = hub.RegisterConstructor("config", func(data int) *mockConfig {
+			return &mockConfig{Value: data}
+		}, sen.WithLifetime(sen.Scoped))
+
+		scope := hub.NewScope()
+		_ = scope.Register("data", 10)
+		cfg, err := sen.ResolveNamed[*mockConfig](scope, "config")
+		if err != nil || cfg.Value != 10 {
+			t.Errorf("Unexpected config %v, err %v", cfg, err)
+		}
+	})
+
+	t.Run("should return error if a singleton depends on a scoped component", func(t *testing.T) {
+		hub := sen.NewHub()
+		_ = hub.RegisterConstructor("config", func() *mockConfig {
+			return &mockConfig{}
+		}, sen.WithLifetime(sen.Scoped))
+		_ = hub.RegisterConstructor("data", func(cfg *mockConfig) int {
+			return cfg.Value
+		})
+
+		_, err := hub.NewScope().Retrieve("data")
+		if fmt.Sprintf("%v", err) != "hub: singleton data can't depend on scoped config" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should return error if a scoped component is resolved outside a scope", func(t *testing.T) {
+		hub := sen.NewHub()
+		_ = hub.RegisterConstructor("config", func() *mockConfig {
+			return &mockConfig{}
+		}, sen.WithLifetime(sen.Scoped))
+
+		_, err := hub.Retrieve("config")
+		if fmt.Sprintf("%v", err) != "hub: scoped config can only be resolved from a scope" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err = hub.Register("need-config", &struct {
+			Config *mockConfig `inject:"config"`
+		}{})
+		if !strings.Contains(fmt.Sprintf("%v", err), "hub: scoped config can only be resolved from a scope") {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
+
+type mockNamers struct {
+	List []mockNamer          `inject:"*,all"`
+	Map  map[string]mockNamer `inject:"*,all"`
+}
+
+func TestHub_InjectAll(t *testing.T) {
+	t.Run("should inject all matching components in the registration order", func(t *testing.T) {
+		hub := sen.NewHub()
+		for _, name := range []string{"c", "a", "b"} {
+			if err := hub.Register(name, &mockNamerImpl{name: name}); err != nil {
+				t.Errorf("Unexpected err %v", err)
+			}
+		}
+
+		scope := hub.NewScope()
+		_ = scope.Register("a", &mockNamerImpl{name: "scoped-a"})
+		_ = scope.Register("d", &mockNamerImpl{name: "d"})
+
+		component := &mockNamers{}
+		if err := scope.Inject(component); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		var names []string
+		for _, n := range component.List {
+			names = append(names, n.Name())
+		}
+
+		if fmt.Sprint(names) != "[c scoped-a b d]" {
+			t.Errorf("Unexpected components %v", names)
+		}
+
+		if len(component.Map) != 4 || component.Map["a"].Name() != "scoped-a" {
+			t.Errorf("Unexpected components %v", component.Map)
+		}
+	})
+
+	t.Run("should return an error if the field isn't a slice or a map", func(t *testing.T) {
+		hub := sen.NewHub()
+		err := hub.Inject(&struct {
+			Namer mockNamer `inject:"*,all"`
+		}{})
+		if fmt.Sprintf("%v", err) != `hub: sen_test.mockNamer is not injectable with all, a slice or a map keyed by string is expected (injecting *struct { Namer sen_test.mockNamer "inject:\"*,all\"" }.Namer)` {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
+
+type mockQualifiedComponent struct {
+	Primary  *mockConfig `inject:"*"`
+	ReadOnly *mockConfig `inject:"*,qualifier=readonly"`
+}
+
+func TestHub_Primary(t *testing.T) {
+	t.Run("should inject the primary component if there are multiple candidates", func(t *testing.T) {
+		hub := sen.NewHub()
+		primary := &mockConfig{Value: 1}
+		replica := &mockConfig{Value: 2}
+		_ = hub.Register("replica", replica, sen.WithQualifier("readonly"))
+		_ = hub.Register("primary", primary, sen.AsPrimary())
+
+		component := &mockQualifiedComponent{}
+		if err := hub.Inject(component); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if component.Primary != primary || component.ReadOnly != replica {
+			t.Errorf("Unexpected components %v and %v", component.Primary, component.ReadOnly)
+		}
+	})
+
+	t.Run("should return an error if there is no primary component", func(t *testing.T) {
+		hub := sen.NewHub()
+		_ = hub.Register("a", &mockConfig{})
+		_ = hub.Register("b", &mockConfig{})
+
+		_, err := sen.Resolve[*mockConfig](hub)
+		if fmt.Sprintf("%v", err) != "hub: there is a conflict when finding the dependency for *sen_test.mockConfig: a, b" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should return an error if there are multiple primary components", func(t *testing.T) {
+		hub := sen.NewHub()
+		_ = hub.Register("a", &mockConfig{}, sen.AsPrimary())
+		_ = hub.Register("b", &mockConfig{}, sen.AsPrimary())
+
+		_, err := sen.Resolve[*mockConfig](hub)
+		if fmt.Sprintf("%v", err) != "hub: there are multiple primary dependencies for *sen_test.mockConfig: a, b" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should return an error if a qualifier is used with a name", func(t *testing.T) {
+		hub := sen.NewHub()
+		err := hub.Inject(&struct {
+			Config *mockConfig `inject:"config,qualifier=readonly"`
+		}{})
+		if !strings.HasPrefix(fmt.Sprintf("%v", err), "hub: qualifier is only supported with *") {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should return an error if a component without a constructor is transient", func(t *testing.T) {
+		hub := sen.NewHub()
+		err := hub.Register("data", 10, sen.WithLifetime(sen.Transient))
+		if fmt.Sprintf("%v", err) != "hub: data can't be transient, only components with constructors can" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
+
+func TestHub_Concurrency(t *testing.T) {
+	t.Run("should allow registering and retrieving components concurrently", func(t *testing.T) {
+		hub := sen.NewHub()
+		called := int32(0)
+		_ = hub.RegisterConstructor("config", func() *mockConfig {
+			atomic.AddInt32(&called, 1)
+			return &mockConfig{}
+		})
+
+		wg := &sync.WaitGroup{}
+		for i := 0; i < 50; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := hub.Register(fmt.Sprintf("data-%d", i), i); err != nil {
+					t.Errorf("Unexpected err %v", err)
+				}
+
+				if _, err := hub.Retrieve("config"); err != nil {
+					t.Errorf("Unexpected err %v", err)
+				}
+
+				scope := hub.NewScope()
+				component := &mockNamers{}
+				_ = scope.Register("namer", &mockNamerImpl{name: "namer"})
+				if err := scope.Inject(component); err != nil {
+					t.Errorf("Unexpected err %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if called != 1 {
+			t.Errorf("Expected the constructor is called once but got %d", called)
+		}
+	})
+
+	t.Run("should construct scoped components in different scopes concurrently", func(t *testing.T) {
+		hub := sen.NewHub()
+		called := int32(0)
+		blockCh := make(chan struct{})
+		_ = hub.RegisterConstructor("config", func() *mockConfig {
+			if atomic.AddInt32(&called, 1) == 1 {
+				<-blockCh
+			}
+			return &mockConfig{}
+		}, sen.WithLifetime(sen.Scoped))
+
+		go func() {
+			_, _ = hub.NewScope().Retrieve("config")
+		}()
+
+		for atomic.LoadInt32(&called) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		if _, err := hub.NewScope().Retrieve("config"); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+		close(blockCh)
+	})
+}
+
+func TestHub_Seal(t *testing.T) {
+	t.Run("should return an error if a component is registered after the hub is sealed", func(t *testing.T) {
+		app := sen.New(sen.SealHubOnRun())
+		m := &mockHubPlugin{}
+		if err := app.With(m); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err := m.Hub.Register("data", 10)
+		if !errors.Is(err, sen.ErrHubSealed) {
+			t.Errorf("Expected ErrHubSealed but got %v", err)
+		}
+
+		if err := m.Hub.NewScope().Register("data", 10); err != nil {
+			t.Errorf("Expected scopes aren't sealed but got %v", err)
+		}
+	})
+}
+
+type mockHubPlugin struct {
+	Hub sen.Hub `inject:"hub"`
+}
+
+func (mockHubPlugin) Initialize() error {
+	return nil
+}
@@ -2,62 +2,183 @@ package sen
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
 	"sync"
-
-	"golang.org/x/sync/errgroup"
+	"sync/atomic"
+	"time"
 )
 
 // Lifecycle manages the lifecycle of an application.
 // An application starts with .Run(ctx) and will be stopped
 // when .Shutdown(ctx) is called.
-// It also allows to hook into the application via OnRun, OnShutdown and PostRun.
+// It also allows to hook into the application via OnStart, OnRun, OnShutdown and PostRun.
+// Hooks can be named via WithHookName and they are owned by the plugin registering them,
+// so they can be identified in errors and events sent to observers.
+//
+// Phases are executed in the following order:
+//   - OnStart hooks are executed one by one in the registration order.
+//     Ready is signaled once all of them succeed.
+//   - OnRun hooks are executed in parallel. A hook can be supervised via WithRestartPolicy
+//     so it's restarted instead of shutting down the application.
+//   - OnShutdown hooks are executed one by one in the reverse registration order
+//     when Shutdown is called or all OnRun hooks return. Once the context given to Shutdown
+//     is done, the remaining hooks are skipped and OnRun hooks are no longer waited for.
+//     The context of Run isn't used for shutting down so the application still shuts down
+//     gracefully when it's canceled.
+//   - PostRun hooks are executed at last.
+//
+// OnReload hooks are executed one by one whenever Reload is called, e.g. to re-read
+// configurations. Their errors are reported but they don't stop the application.
+//
+// A panic in a hook is recovered and reported as a *PanicError so the following
+// phases are still executed.
 type Lifecycle interface {
-	OnRun(h Hook)
-	OnShutdown(h Hook)
-	PostRun(h Hook)
+	OnStart(h Hook, opts ...HookOption)
+	OnRun(h Hook, opts ...HookOption)
+	OnShutdown(h Hook, opts ...HookOption)
+	PostRun(h Hook, opts ...HookOption)
+	OnReload(h Hook, opts ...HookOption)
 	Run(ctx context.Context) error
 	Shutdown(ctx context.Context) error
+	Reload(ctx context.Context) error
+
+	// Ready returns a channel that is closed once all OnStart hooks succeed.
+	Ready() <-chan struct{}
+
+	// Observe adds an observer to receive events when hooks are executed.
+	Observe(o HookObserver)
 }
 
 type defaultLifecycle struct {
-	runHooks      []Hook
-	shutdownHooks []Hook
-	postRunHooks  []Hook
+	mu            sync.Mutex
+	startHooks    []*lifecycleHook
+	runHooks      []*lifecycleHook
+	shutdownHooks []*lifecycleHook
+	postRunHooks  []*lifecycleHook
+	reloadHooks   []*lifecycleHook
+	reloadMu      sync.Mutex
+	observers     []HookObserver
+	phaseHooks    []func(p Phase)
+	owner         string
+	startTimeout  time.Duration
+	started       atomic.Bool
 	shutdownOnce  func(ctx context.Context) error
+	ready         chan struct{}
+	stopping      chan struct{}
+	// abandoned is closed if the shutdown phase runs out of time
+	// so OnRun hooks that are still running are abandoned.
+	abandoned chan struct{}
+}
+
+// OnStart adds additional logic to prepare the app before it runs, e.g. warming up caches.
+// Hooks are executed one by one and OnRun hooks are only executed after all of them succeed.
+func (lc *defaultLifecycle) OnStart(h Hook, opts ...HookOption) {
+	lc.add(&lc.startHooks, h, PhaseStart, opts)
+}
+
+// onStartFirst adds hooks to be executed before all existing OnStart hooks.
+func (lc *defaultLifecycle) onStartFirst(hooks ...*lifecycleHook) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.startHooks = append(append([]*lifecycleHook(nil), hooks...), lc.startHooks...)
 }
 
 // OnRun adds additional logic when the app runs. For a long lasting service
 // it should only block the function until the service no longer runs.
-func (lc *defaultLifecycle) OnRun(h Hook) {
-	lc.runHooks = append(lc.runHooks, h)
+func (lc *defaultLifecycle) OnRun(h Hook, opts ...HookOption) {
+	lc.add(&lc.runHooks, h, PhaseRun, opts)
 }
 
 // PostRun adds additional logic after all services stop running
 // and shutdown logic is executed.
 // It's useful for syncing logs, etc.
-func (lc *defaultLifecycle) PostRun(h Hook) {
-	lc.postRunHooks = append(lc.postRunHooks, h)
+func (lc *defaultLifecycle) PostRun(h Hook, opts ...HookOption) {
+	lc.add(&lc.postRunHooks, h, PhasePostRun, opts)
+}
+
+// OnReload adds additional logic to reload the app without restarting it,
+// e.g. rotating credentials.
+func (lc *defaultLifecycle) OnReload(h Hook, opts ...HookOption) {
+	lc.add(&lc.reloadHooks, h, PhaseReload, opts)
 }
 
 // OnShutdown adds additional logic when the app shuts down.
-func (lc *defaultLifecycle) OnShutdown(h Hook) {
-	lc.shutdownHooks = append(lc.shutdownHooks, h)
+// Shutdown hooks are executed one by one in the reverse registration order
+// so components are shut down before their dependencies.
+// A name and a timeout can be given via WithHookName and WithHookTimeout.
+func (lc *defaultLifecycle) OnShutdown(h Hook, opts ...HookOption) {
+	lc.add(&lc.shutdownHooks, h, PhaseShutdown, opts)
+}
+
+// onShutdownLast adds hooks to be executed after all existing OnShutdown hooks.
+// As shutdown hooks are executed in the reverse order, the given hooks are executed
+// in the reverse order as well.
+func (lc *defaultLifecycle) onShutdownLast(hooks ...*lifecycleHook) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.shutdownHooks = append(append([]*lifecycleHook(nil), hooks...), lc.shutdownHooks...)
 }
 
 // Run runs the application by executing all the registered hooks for this phase.
-func (lc *defaultLifecycle) Run(ctx context.Context) (err error) {
-	err = executeHooks(ctx, lc.runHooks)
-	shutdownErr := lc.shutdownOnce(ctx)
-	if shutdownErr != nil && err == nil {
-		err = shutdownErr
+// Errors from all phases are aggregated into a *LifecycleError.
+// A lifecycle can only run once, otherwise ErrInvalidState is returned.
+func (lc *defaultLifecycle) Run(ctx context.Context) error {
+	if !lc.started.CompareAndSwap(false, true) {
+		return fmt.Errorf("sen: unable to run the lifecycle more than once: %w", ErrInvalidState)
 	}
 
-	afterRunErr := executeHooks(ctx, lc.postRunHooks)
-	if afterRunErr != nil && err == nil {
-		err = afterRunErr
+	lc.enterPhase(PhaseStart)
+	errs := lc.start(ctx)
+	if len(errs) == 0 && !lc.isShuttingDown() {
+		close(lc.ready)
+		lc.enterPhase(PhaseRun)
+		errs = append(errs, lc.executeHooks(ctx, lc.hooks(&lc.runHooks), lc.abandoned)...)
 	}
 
-	return
+	// the application still shuts down gracefully if ctx is canceled, e.g. via signal.NotifyContext.
+	ctx = detachedContext{ctx}
+	errs = appendErrors(errs, lc.shutdownOnce(ctx))
+	lc.enterPhase(PhasePostRun)
+	errs = append(errs, lc.executeHooks(ctx, lc.hooks(&lc.postRunHooks), nil)...)
+	return newLifecycleError(errs)
+}
+
+// detachedContext keeps values of its parent but it isn't canceled along with the parent.
+type detachedContext struct {
+	context.Context
+}
+
+// Deadline returns no deadline as the context is never canceled.
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done returns nil as the context is never canceled.
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err returns nil as the context is never canceled.
+func (detachedContext) Err() error {
+	return nil
+}
+
+// start executes OnStart hooks within the start timeout if there is one.
+func (lc *defaultLifecycle) start(ctx context.Context) []error {
+	if lc.startTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lc.startTimeout)
+		defer cancel()
+	}
+
+	return lc.executeSequentially(ctx, lc.hooks(&lc.startHooks))
+}
+
+// Ready returns a channel that is closed once all OnStart hooks succeed.
+func (lc *defaultLifecycle) Ready() <-chan struct{} {
+	return lc.ready
 }
 
 // Shutdown runs the application by executing all the registered hooks for this phase.
@@ -65,28 +186,306 @@ func (lc *defaultLifecycle) Shutdown(ctx context.Context) error {
 	return lc.shutdownOnce(ctx)
 }
 
+// Reload executes all OnReload hooks one by one in the registration order.
+// All hooks are executed even if some of them fail and their errors are aggregated
+// into a *LifecycleError. Reloads are executed one at a time.
+func (lc *defaultLifecycle) Reload(ctx context.Context) error {
+	lc.reloadMu.Lock()
+	defer lc.reloadMu.Unlock()
+
+	var errs []error
+	for _, h := range lc.hooks(&lc.reloadHooks) {
+		if err := lc.execute(ctx, h); err != nil {
+			errs = append(errs, h.error(err))
+		}
+	}
+
+	return newLifecycleError(errs)
+}
+
 // internalShutdown is the internal implementation of the shutdown function.
 // It shouldn't be called multiple times so it should be wrapped to run once only.
+// Hooks are executed in the reverse order even if some of them fail or time out.
+// Once ctx is done, the remaining hooks are skipped and OnRun hooks are no longer waited for.
 func (lc *defaultLifecycle) internalShutdown(ctx context.Context) error {
-	return executeHooks(ctx, lc.shutdownHooks)
+	close(lc.stopping)
+	lc.enterPhase(PhaseShutdown)
+	hooks := lc.hooks(&lc.shutdownHooks)
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := lc.execute(ctx, hooks[i]); err != nil {
+			errs = append(errs, hooks[i].error(err))
+		}
+	}
+
+	if ctx.Err() != nil {
+		close(lc.abandoned)
+	}
+
+	return newLifecycleError(errs)
 }
 
-func newLifecycle() Lifecycle {
-	lc := &defaultLifecycle{}
+// isShuttingDown returns whether Shutdown has been called.
+func (lc *defaultLifecycle) isShuttingDown() bool {
+	select {
+	case <-lc.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// untilShutdown wraps a run hook so its context is canceled once shutdown begins.
+// The error of the canceled context isn't reported as the hook is asked to stop.
+func (lc *defaultLifecycle) untilShutdown(h Hook) Hook {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		go func() {
+			select {
+			case <-lc.stopping:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		if err := h(ctx); err != nil && !(lc.isShuttingDown() && errors.Is(err, context.Canceled)) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// add appends a hook into the given hooks.
+// The hook is owned by the plugin being initialized if its owner isn't given.
+func (lc *defaultLifecycle) add(hooks *[]*lifecycleHook, h Hook, phase Phase, opts []HookOption) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	hook := newLifecycleHook(h, phase, len(*hooks), opts)
+	if hook.owner == "" {
+		hook.owner = lc.owner
+	}
+
+	*hooks = append(*hooks, hook)
+}
+
+// setOwner sets the owner of hooks registered from now on and returns the previous one.
+func (lc *defaultLifecycle) setOwner(owner string) string {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	prev := lc.owner
+	lc.owner = owner
+	return prev
+}
+
+// onPhase adds a function to be called whenever a phase begins.
+func (lc *defaultLifecycle) onPhase(fn func(p Phase)) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.phaseHooks = append(lc.phaseHooks, fn)
+}
+
+func (lc *defaultLifecycle) enterPhase(p Phase) {
+	lc.mu.Lock()
+	phaseHooks := append([]func(Phase){}, lc.phaseHooks...)
+	lc.mu.Unlock()
+
+	for _, fn := range phaseHooks {
+		fn(p)
+	}
+}
+
+// hooks returns a copy of the given hooks so they can be executed
+// while new hooks are being added.
+func (lc *defaultLifecycle) hooks(hooks *[]*lifecycleHook) []*lifecycleHook {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return append([]*lifecycleHook(nil), *hooks...)
+}
+
+func newLifecycle() *defaultLifecycle {
+	lc := &defaultLifecycle{
+		ready:     make(chan struct{}),
+		stopping:  make(chan struct{}),
+		abandoned: make(chan struct{}),
+	}
 	lc.shutdownOnce = runOnce(lc.internalShutdown)
 	return lc
 }
 
-func executeHooks(ctx context.Context, hooks []Hook) error {
-	eg, ctx := errgroup.WithContext(ctx)
+// executeHooks executes hooks in parallel and returns errors of all failed hooks
+// in the registration order. Once a hook fails, the context of other hooks is canceled
+// and their context.Canceled errors aren't reported.
+// Hooks still running when abandon is closed are no longer waited for.
+func (lc *defaultLifecycle) executeHooks(ctx context.Context, hooks []*lifecycleHook, abandon <-chan struct{}) []error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	wg := &sync.WaitGroup{}
+	mu := &sync.Mutex{}
+	failed := false
+	hookErrs := make([]error, len(hooks))
+	for i, h := range hooks {
+		i, h := i, h
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := lc.supervise(ctx, h)
+			if err == nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if failed && errors.Is(err, context.Canceled) {
+				return
+			}
+
+			failed = true
+			hookErrs[i] = h.error(err)
+			cancel()
+		}()
+	}
+
+	waitCh := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(waitCh)
+	}()
+
+	select {
+	case <-waitCh:
+	case <-abandon:
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var errs []error
+	for _, err := range hookErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// Phase represents a phase in the application lifecycle.
+type Phase string
+
+// Phases of the application lifecycle.
+const (
+	PhaseStart    Phase = "start"
+	PhaseRun      Phase = "run"
+	PhaseShutdown Phase = "shutdown"
+	PhasePostRun  Phase = "post-run"
+	PhaseReload   Phase = "reload"
+)
+
+// lifecycleHook is a hook with its name and options.
+type lifecycleHook struct {
+	hook       Hook
+	phase      Phase
+	name       string
+	owner      string
+	timeout    time.Duration
+	supervisor supervisorOptions
+}
+
+func newLifecycleHook(h Hook, phase Phase, idx int, opts []HookOption) *lifecycleHook {
+	options := &hookOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	name := options.name
+	if name == "" {
+		name = fmt.Sprintf("#%d", idx)
+	}
+
+	return &lifecycleHook{
+		hook:       h,
+		phase:      phase,
+		name:       name,
+		owner:      options.owner,
+		timeout:    options.timeout,
+		supervisor: options.supervisor,
+	}
+}
+
+// execute executes the hook. If the hook has a timeout, it returns ErrHookTimeout
+// when the hook doesn't return in time. A panic in the hook is returned as a *PanicError.
+// Start and shutdown phases are bounded by ctx: the running hook is abandoned once ctx is done
+// and hooks that haven't run are skipped with ErrHookTimeout.
+func (h *lifecycleHook) execute(ctx context.Context) error {
+	bounded := h.phase == PhaseStart || h.phase == PhaseShutdown
+	if bounded && ctx.Err() != nil {
+		return fmt.Errorf("%w before it started: %w", ErrHookTimeout, ctx.Err())
+	}
+
+	if h.timeout <= 0 && (!bounded || ctx.Done() == nil) {
+		return h.call(ctx)
+	}
+
+	parent := ctx
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.call(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s", ErrHookTimeout, h.timeout)
+		}
+
+		return ctx.Err()
+	}
+}
+
+// call calls the hook and recovers from its panic.
+func (h *lifecycleHook) call(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{
+				Value: r,
+				Stack: debug.Stack(),
+			}
+		}
+	}()
+
+	return h.hook(ctx)
+}
+
+// error wraps an error returned by the hook into a *HookError.
+func (h *lifecycleHook) error(err error) error {
+	return &HookError{
+		Phase: h.phase,
+		Name:  h.name,
+		Owner: h.owner,
+		Err:   err,
+	}
+}
+
+// executeSequentially executes hooks one by one and stops at the first failed hook.
+func (lc *defaultLifecycle) executeSequentially(ctx context.Context, hooks []*lifecycleHook) []error {
 	for _, h := range hooks {
-		h := h
-		eg.Go(func() error {
-			return h(ctx)
-		})
+		if err := lc.execute(ctx, h); err != nil {
+			return []error{h.error(err)}
+		}
 	}
 
-	return eg.Wait()
+	return nil
 }
 
 // runOnce allows creates a function that will call fn only once.
@@ -101,6 +500,14 @@ func runOnce(fn func(ctx context.Context) error) func(ctx context.Context) error
 			err = fn(ctx)
 			close(done)
 		})
+
+		// the error of fn takes precedence if both are ready.
+		select {
+		case <-done:
+			return err
+		default:
+		}
+
 		select {
 		case <-done:
 			return err
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +12,18 @@ import (
 )
 
 func TestLifecycle(t *testing.T) {
+	t.Run("should return error if it runs more than once", func(t *testing.T) {
+		lc := sen.NewLifecycle()
+		if err := lc.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err := lc.Run(context.Background())
+		if !errors.Is(err, sen.ErrInvalidState) {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
 	t.Run("should run all hooks for OnRun stage", func(t *testing.T) {
 		hook1Called := 0
 		hook2Called := 0
@@ -102,7 +115,7 @@ func TestLifecycle(t *testing.T) {
 		})
 
 		err := lc.Run(context.Background())
-		if fmt.Sprintf("%v", err) != "random error" {
+		if fmt.Sprintf("%v", err) != "run hook #1: random error\nrun hook #2: random error" {
 			t.Errorf("Unexpected err %v", err)
 		}
 		select {
@@ -131,7 +144,7 @@ func TestLifecycle(t *testing.T) {
 		})
 
 		err := lc.Run(context.Background())
-		if fmt.Sprintf("%v", err) != "run error" {
+		if fmt.Sprintf("%v", err) != "run hook #0: run error" {
 			t.Errorf("Unexpected error: %v", err)
 		}
 
@@ -177,3 +190,406 @@ func TestLifecycle(t *testing.T) {
 		}
 	})
 }
+
+func TestLifecycle_Errors(t *testing.T) {
+	t.Run("should report errors from all phases", func(t *testing.T) {
+		runErr := errors.New("run error")
+		lc := sen.NewLifecycle()
+		lc.OnRun(func(_ context.Context) error {
+			return runErr
+		})
+
+		lc.OnShutdown(func(_ context.Context) error {
+			return errors.New("shutdown error")
+		}, sen.WithHookName("db"))
+
+		lc.PostRun(func(_ context.Context) error {
+			return errors.New("post-run error")
+		})
+
+		err := lc.Run(context.Background())
+		expectedErr := "run hook #0: run error\n" +
+			"shutdown hook db: shutdown error\n" +
+			"post-run hook #0: post-run error"
+		if fmt.Sprintf("%v", err) != expectedErr {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if !errors.Is(err, runErr) {
+			t.Errorf("Expected run error is reported but got %v", err)
+		}
+
+		var lcErr *sen.LifecycleError
+		if !errors.As(err, &lcErr) || len(lcErr.Errors) != 3 {
+			t.Fatalf("Unexpected lifecycle error %v", lcErr)
+		}
+
+		var hookErr *sen.HookError
+		if !errors.As(lcErr.Errors[1], &hookErr) || hookErr.Phase != sen.PhaseShutdown || hookErr.Name != "db" {
+			t.Errorf("Unexpected hook error %v", hookErr)
+		}
+	})
+
+	t.Run("should not report hooks canceled because of another failed hook", func(t *testing.T) {
+		lc := sen.NewLifecycle()
+		lc.OnRun(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		lc.OnRun(func(_ context.Context) error {
+			return errors.New("run error")
+		})
+
+		err := lc.Run(context.Background())
+		if fmt.Sprintf("%v", err) != "run hook #1: run error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
+
+func TestLifecycle_Panic(t *testing.T) {
+	t.Run("should recover from a panic and still run other phases", func(t *testing.T) {
+		var calls []string
+		lc := sen.NewLifecycle()
+		lc.OnRun(func(_ context.Context) error {
+			panic("run panic")
+		}, sen.WithHookName("worker"))
+
+		lc.OnShutdown(func(_ context.Context) error {
+			calls = append(calls, "shutdown")
+			return nil
+		})
+
+		lc.PostRun(func(_ context.Context) error {
+			calls = append(calls, "post-run")
+			return nil
+		})
+
+		err := lc.Run(context.Background())
+		var panicErr *sen.PanicError
+		if !errors.As(err, &panicErr) || panicErr.Value != "run panic" {
+			t.Fatalf("Expected PanicError but got %v", err)
+		}
+
+		if !strings.HasPrefix(err.Error(), "run hook worker: sen: recovered from panic: run panic\n") {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if !strings.Contains(string(panicErr.Stack), "lifecycle_test.go") {
+			t.Errorf("Expected the stack trace of the panic but got %s", panicErr.Stack)
+		}
+
+		if fmt.Sprint(calls) != "[shutdown post-run]" {
+			t.Errorf("Unexpected calls %v", calls)
+		}
+	})
+
+	t.Run("should recover from a panic in a hook with a timeout", func(t *testing.T) {
+		panicErr := errors.New("panic error")
+		lc := sen.NewLifecycle()
+		lc.OnShutdown(func(_ context.Context) error {
+			panic(panicErr)
+		}, sen.WithHookTimeout(time.Second))
+
+		err := lc.Shutdown(context.Background())
+		if !errors.Is(err, panicErr) {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
+
+func TestLifecycle_Reload(t *testing.T) {
+	t.Run("should run all OnReload hooks and report failed ones", func(t *testing.T) {
+		var calls []string
+		lc := sen.NewLifecycle()
+		lc.OnReload(func(_ context.Context) error {
+			calls = append(calls, "config")
+			return errors.New("reload error")
+		}, sen.WithHookName("config"))
+
+		lc.OnReload(func(_ context.Context) error {
+			calls = append(calls, "credentials")
+			return nil
+		})
+
+		err := lc.Reload(context.Background())
+		if fmt.Sprintf("%v", err) != "reload hook config: reload error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if fmt.Sprint(calls) != "[config credentials]" {
+			t.Errorf("Unexpected calls %v", calls)
+		}
+	})
+
+	t.Run("should keep running if reloading fails", func(t *testing.T) {
+		app := sen.New()
+		m := makeMockWaitingPlugin()
+		err := app.With(m, sen.OnReload(func(_ context.Context) error {
+			return errors.New("reload error")
+		}))
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		doneCh := make(chan error)
+		go func() {
+			doneCh <- app.Run(context.Background())
+		}()
+
+		<-m.ready
+		err = app.Reload(context.Background())
+		if fmt.Sprintf("%v", err) != "reload hook #0: reload error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		select {
+		case err := <-doneCh:
+			t.Fatalf("Expected the app still runs but got %v", err)
+		default:
+		}
+
+		if err := app.Shutdown(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := <-doneCh; err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
+
+func TestLifecycle_OnStart(t *testing.T) {
+	t.Run("should run OnStart hooks in order before OnRun hooks", func(t *testing.T) {
+		var calls []string
+		lc := sen.NewLifecycle()
+		lc.OnRun(func(_ context.Context) error {
+			select {
+			case <-lc.Ready():
+			default:
+				t.Errorf("Expected the lifecycle is ready")
+			}
+
+			calls = append(calls, "run")
+			return nil
+		})
+
+		lc.OnStart(func(_ context.Context) error {
+			calls = append(calls, "start1")
+			return nil
+		})
+
+		lc.OnStart(func(_ context.Context) error {
+			calls = append(calls, "start2")
+			return nil
+		})
+
+		err := lc.Run(context.Background())
+		if err != nil {
+			t.Errorf("Expected no error but got %v", err)
+		}
+
+		if fmt.Sprint(calls) != "[start1 start2 run]" {
+			t.Errorf("Unexpected calls %v", calls)
+		}
+	})
+
+	t.Run("should skip OnRun hooks but still shut down if an OnStart hook fails", func(t *testing.T) {
+		var calls []string
+		lc := sen.NewLifecycle()
+		lc.OnStart(func(_ context.Context) error {
+			calls = append(calls, "start")
+			return errors.New("start error")
+		})
+
+		lc.OnRun(func(_ context.Context) error {
+			calls = append(calls, "run")
+			return nil
+		})
+
+		lc.OnShutdown(func(_ context.Context) error {
+			calls = append(calls, "shutdown")
+			return nil
+		})
+
+		err := lc.Run(context.Background())
+		if fmt.Sprintf("%v", err) != "start hook #0: start error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if fmt.Sprint(calls) != "[start shutdown]" {
+			t.Errorf("Unexpected calls %v", calls)
+		}
+
+		select {
+		case <-lc.Ready():
+			t.Errorf("Expected the lifecycle isn't ready")
+		default:
+		}
+	})
+
+	t.Run("should not execute OnStart hooks if the context is already done", func(t *testing.T) {
+		started := false
+		lc := sen.NewLifecycle()
+		lc.OnStart(func(_ context.Context) error {
+			started = true
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := lc.Run(ctx)
+		if !errors.Is(err, sen.ErrHookTimeout) || !errors.Is(err, context.Canceled) {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		if started {
+			t.Errorf("The hook shouldn't be executed")
+		}
+	})
+}
+
+func TestLifecycle_OnShutdown(t *testing.T) {
+	t.Run("should run OnShutdown hooks in the reverse registration order", func(t *testing.T) {
+		var calls []string
+		lc := sen.NewLifecycle()
+		lc.OnShutdown(func(_ context.Context) error {
+			calls = append(calls, "db")
+			return nil
+		})
+
+		lc.OnShutdown(func(_ context.Context) error {
+			calls = append(calls, "server")
+			return nil
+		})
+
+		err := lc.Run(context.Background())
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if fmt.Sprint(calls) != "[server db]" {
+			t.Errorf("Unexpected calls %v", calls)
+		}
+	})
+
+	t.Run("should run all OnShutdown hooks and report failed ones", func(t *testing.T) {
+		var calls []string
+		lc := sen.NewLifecycle()
+		lc.OnShutdown(func(_ context.Context) error {
+			calls = append(calls, "db")
+			return nil
+		}, sen.WithHookName("db"))
+
+		lc.OnShutdown(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}, sen.WithHookName("server"), sen.WithHookTimeout(10*time.Millisecond))
+
+		lc.OnShutdown(func(_ context.Context) error {
+			calls = append(calls, "cache")
+			return errors.New("random error")
+		}, sen.WithHookName("cache"))
+
+		err := lc.Run(context.Background())
+		if !errors.Is(err, sen.ErrHookTimeout) {
+			t.Errorf("Expected ErrHookTimeout but got %v", err)
+		}
+
+		expectedErr := "shutdown hook cache: random error\n" +
+			"shutdown hook server: sen: the hook timed out after 10ms"
+		if fmt.Sprintf("%v", err) != expectedErr {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		var hookErr *sen.HookError
+		if !errors.As(err, &hookErr) || hookErr.Name != "cache" || hookErr.Phase != sen.PhaseShutdown {
+			t.Errorf("Unexpected hook error %v", hookErr)
+		}
+
+		if fmt.Sprint(calls) != "[cache db]" {
+			t.Errorf("Unexpected calls %v", calls)
+		}
+	})
+
+	t.Run("should give up waiting for a hook that ignores its timeout", func(t *testing.T) {
+		lc := sen.NewLifecycle()
+		blockCh := make(chan struct{})
+		defer close(blockCh)
+		lc.OnShutdown(func(_ context.Context) error {
+			<-blockCh
+			return nil
+		}, sen.WithHookTimeout(10*time.Millisecond))
+
+		err := lc.Shutdown(context.Background())
+		if fmt.Sprintf("%v", err) != "shutdown hook #0: sen: the hook timed out after 10ms" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should shut down gracefully if the context of Run is canceled", func(t *testing.T) {
+		var calls []string
+		ctx, cancel := context.WithCancel(context.Background())
+		lc := sen.NewLifecycle()
+		lc.OnRun(func(ctx context.Context) error {
+			cancel()
+			return nil
+		})
+
+		for _, name := range []string{"db", "server"} {
+			name := name
+			lc.OnShutdown(func(ctx context.Context) error {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				calls = append(calls, name)
+				return nil
+			}, sen.WithHookName(name))
+		}
+
+		if err := lc.Run(ctx); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if fmt.Sprint(calls) != "[server db]" {
+			t.Errorf("Unexpected calls %v", calls)
+		}
+	})
+
+	t.Run("should skip remaining hooks once the context is done", func(t *testing.T) {
+		var calls []string
+		blockCh := make(chan struct{})
+		defer close(blockCh)
+		lc := sen.NewLifecycle()
+		lc.OnShutdown(func(_ context.Context) error {
+			calls = append(calls, "db")
+			return nil
+		}, sen.WithHookName("db"))
+
+		lc.OnShutdown(func(_ context.Context) error {
+			<-blockCh
+			return nil
+		}, sen.WithHookName("server"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := lc.Shutdown(ctx)
+		expectedErr := "shutdown hook server: context deadline exceeded\n" +
+			"shutdown hook db: sen: the hook timed out before it started: context deadline exceeded"
+		if fmt.Sprintf("%v", err) != expectedErr {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if !errors.Is(err, sen.ErrHookTimeout) {
+			t.Errorf("Expected ErrHookTimeout but got %v", err)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		if len(calls) > 0 {
+			t.Errorf("Unexpected calls %v", calls)
+		}
+	})
+}
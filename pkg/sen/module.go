@@ -0,0 +1,84 @@
+package sen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ModulePlugin is a plugin that applies a set of plugins into its own namespace.
+// It's created via Module.
+type ModulePlugin struct {
+	App *Application `inject:"app"`
+	Hub Hub          `inject:"hub"`
+
+	name    string
+	plugins []Plugin
+	exports []string
+}
+
+// Module creates a plugin that applies plugins into a child hub named after the module.
+// Plugins in the module resolve components from the module first and then from the parent,
+// while components registered by them are private to the module unless they are exported via Export.
+// Exported components are registered into the parent with the module name as a prefix,
+// so the same set of plugins can be included multiple times without name collisions.
+// Exported transient and scoped components are constructed with arguments from the module
+// first and then from the hub or scope they are requested from.
+//
+// # Usage
+//
+//	app.With(
+//		sen.Module("payments", postgresgorm.Bundle()).Export("gorm"),
+//		sen.Module("orders", postgresgorm.Bundle()).Export("gorm"),
+//	)
+//
+// The databases above are available as "payments.gorm" and "orders.gorm".
+func Module(name string, plugins ...Plugin) *ModulePlugin {
+	return &ModulePlugin{
+		name:    name,
+		plugins: plugins,
+	}
+}
+
+// Export exports components of the module to the parent hub.
+// A component named "gorm" in the module "payments" is exported as "payments.gorm".
+func (m *ModulePlugin) Export(names ...string) *ModulePlugin {
+	m.exports = append(m.exports, names...)
+	return m
+}
+
+// Initialize applies plugins of the module.
+func (m *ModulePlugin) Initialize() error {
+	return m.InitializeContext(context.Background())
+}
+
+// InitializeContext applies plugins of the module with the given context.
+func (m *ModulePlugin) InitializeContext(ctx context.Context) error {
+	parent, ok := m.Hub.(*defaultHub)
+	if !ok {
+		return errors.New("sen: modules can only be applied via Application.With")
+	}
+
+	hub := parent.newModule(m.name)
+	m.App.addModule(hub)
+	if err := m.App.with(ctx, hub, m.plugins); err != nil {
+		return err
+	}
+
+	for _, name := range m.exports {
+		if err := hub.export(name, m.name+"."+name); err != nil {
+			return fmt.Errorf("sen: unable to export %s from module %s: %w", name, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *ModulePlugin) provides() []string {
+	names := make([]string, len(m.exports))
+	for i, name := range m.exports {
+		names[i] = m.name + "." + name
+	}
+
+	return names
+}
@@ -0,0 +1,148 @@
+package sen_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+type mockModuleConsumer struct {
+	PaymentsDB *mockDB `inject:"payments.db"`
+	OrdersDB   *mockDB `inject:"orders.db"`
+}
+
+func (mockModuleConsumer) Initialize() error {
+	return nil
+}
+
+type mockScopedService struct {
+	recorder *mockRecorder
+}
+
+func TestModule(t *testing.T) {
+	t.Run("should namespace exported components of modules", func(t *testing.T) {
+		recorder := &mockRecorder{}
+		consumer := &mockModuleConsumer{}
+		constructed := 0
+		app := sen.New()
+		err := app.With(
+			consumer,
+			sen.Component("recorder", recorder),
+			sen.Module("payments", sen.Component("db", &mockDB{})).Export("db"),
+			sen.Module("orders", sen.Constructor("db", func(r *mockRecorder) *mockDB {
+				constructed++
+				return &mockDB{}
+			})).Export("db"),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected err %v", err)
+		}
+
+		if consumer.PaymentsDB == nil || consumer.OrdersDB == nil || consumer.PaymentsDB == consumer.OrdersDB {
+			t.Errorf("Unexpected consumer %v", consumer)
+		}
+
+		if consumer.OrdersDB.Recorder != recorder {
+			t.Errorf("Expected components of modules are injected from the parent")
+		}
+
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if constructed != 1 {
+			t.Errorf("Expected the exported singleton is constructed once but got %d", constructed)
+		}
+
+		if fmt.Sprint(recorder.events) != "[db.start db.start db.stop db.stop]" {
+			t.Errorf("Unexpected events %v", recorder.events)
+		}
+	})
+
+	t.Run("should keep components private unless they are exported", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			sen.Module("payments", sen.Component("data", 10)),
+			&mockPlugin{},
+		)
+
+		if !errors.Is(err, sen.ErrComponentNotRegistered) {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should construct exported non-singletons with private components of the module", func(t *testing.T) {
+		m := &mockHubPlugin{}
+		app := sen.New()
+		err := app.With(
+			m,
+			sen.Module("payments",
+				sen.Component("config", &mockConfig{Value: 10}),
+				sen.Constructor("transient", func(cfg *mockConfig) int {
+					return cfg.Value
+				}, sen.WithLifetime(sen.Transient)),
+				sen.Constructor("scoped", func(cfg *mockConfig, r *mockRecorder) *mockScopedService {
+					return &mockScopedService{recorder: r}
+				}, sen.WithLifetime(sen.Scoped)),
+			).Export("transient", "scoped"),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected err %v", err)
+		}
+
+		data, err := m.Hub.Retrieve("payments.transient")
+		if err != nil || data != 10 {
+			t.Errorf("Unexpected data %v, err %v", data, err)
+		}
+
+		recorder := &mockRecorder{}
+		scope := m.Hub.NewScope()
+		_ = scope.Register("recorder", recorder)
+		first, err := sen.ResolveNamed[*mockScopedService](scope, "payments.scoped")
+		if err != nil || first.recorder != recorder {
+			t.Errorf("Unexpected service %v, err %v", first, err)
+		}
+
+		second, _ := sen.ResolveNamed[*mockScopedService](scope, "payments.scoped")
+		third, _ := sen.ResolveNamed[*mockScopedService](m.Hub.NewScope(), "payments.scoped")
+		if first != second || first == third {
+			t.Errorf("Expected an instance per scope")
+		}
+	})
+
+	t.Run("should return an error if the exported component isn't registered", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(sen.Module("payments").Export("db"))
+		if fmt.Sprintf("%v", err) != "sen: unable to export db from module payments: hub: db is not registered" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should report missing dependencies with the module in the chain", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(sen.Module("payments", &mockPlugin{}))
+		expectedErr := "sen: unable to resolve dependencies\n\thub: data is not registered (injecting *sen_test.mockPlugin.Data in module payments -> *sen_test.mockPlugin)"
+		if fmt.Sprintf("%v", err) != expectedErr {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should name hooks of module components after their namespaces", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			sen.Component("recorder", &mockRecorder{}),
+			sen.Module("payments", sen.Component("db", &mockDB{startErr: errors.New("start error")})),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err = app.Run(context.Background())
+		if fmt.Sprintf("%v", err) != "start hook payments.db: start error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
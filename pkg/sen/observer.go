@@ -0,0 +1,109 @@
+package sen
+
+import (
+	"context"
+	"time"
+)
+
+// HookEventType is the type of a HookEvent.
+type HookEventType int
+
+const (
+	// HookStarted is sent before a hook is executed.
+	HookStarted HookEventType = iota
+	// HookFinished is sent after a hook returns successfully.
+	HookFinished
+	// HookFailed is sent after a hook returns an error or times out.
+	HookFailed
+	// HookRestarting is sent before a supervised hook is restarted.
+	// Its duration is the delay before the restart.
+	HookRestarting
+)
+
+// String returns the name of the event type.
+func (t HookEventType) String() string {
+	switch t {
+	case HookStarted:
+		return "started"
+	case HookFinished:
+		return "finished"
+	case HookFailed:
+		return "failed"
+	case HookRestarting:
+		return "restarting"
+	default:
+		return "unknown"
+	}
+}
+
+// HookEvent describes the execution of a lifecycle hook.
+type HookEvent struct {
+	// Type is the type of the event.
+	Type HookEventType
+	// Phase is the phase the hook belongs to.
+	Phase Phase
+	// Name is the name of the hook.
+	Name string
+	// Owner is the plugin registering the hook.
+	Owner string
+	// Duration is how long the hook took. It's zero for HookStarted events.
+	Duration time.Duration
+	// Err is the error of the hook for HookFailed and HookRestarting events.
+	Err error
+}
+
+// HookObserver receives events when lifecycle hooks are executed.
+// It's called synchronously and possibly from multiple goroutines at the same time
+// so it should be quick and safe for concurrent use.
+//
+// # Usage
+//
+//	lc.Observe(func(e sen.HookEvent) {
+//		log.Printf("%s hook %s %s in %s", e.Phase, e.Name, e.Type, e.Duration)
+//	})
+type HookObserver func(e HookEvent)
+
+// Observe adds an observer to receive events when hooks are executed.
+func (lc *defaultLifecycle) Observe(o HookObserver) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.observers = append(lc.observers, o)
+}
+
+// execute executes a hook and notifies observers about it.
+func (lc *defaultLifecycle) execute(ctx context.Context, h *lifecycleHook) error {
+	lc.notify(HookEvent{
+		Type:  HookStarted,
+		Phase: h.phase,
+		Name:  h.name,
+		Owner: h.owner,
+	})
+
+	startedAt := time.Now()
+	err := h.execute(ctx)
+	event := HookEvent{
+		Type:     HookFinished,
+		Phase:    h.phase,
+		Name:     h.name,
+		Owner:    h.owner,
+		Duration: time.Since(startedAt),
+		Err:      err,
+	}
+
+	if err != nil {
+		event.Type = HookFailed
+	}
+
+	lc.notify(event)
+	return err
+}
+
+func (lc *defaultLifecycle) notify(e HookEvent) {
+	lc.mu.Lock()
+	observers := append([]HookObserver(nil), lc.observers...)
+	lc.mu.Unlock()
+
+	for _, o := range observers {
+		o(e)
+	}
+}
@@ -0,0 +1,123 @@
+package sen_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+type mockObserver struct {
+	mu     sync.Mutex
+	events []sen.HookEvent
+}
+
+func (o *mockObserver) observe(e sen.HookEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, e)
+}
+
+func (o *mockObserver) summary() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	summary := make([]string, len(o.events))
+	for i, e := range o.events {
+		summary[i] = fmt.Sprintf("%s:%s:%s", e.Phase, e.Name, e.Type)
+	}
+
+	return summary
+}
+
+type mockObserverPlugin struct {
+	LC       sen.Lifecycle `inject:"lifecycle"`
+	observer *mockObserver
+}
+
+func (p *mockObserverPlugin) Initialize() error {
+	p.LC.Observe(p.observer.observe)
+	return nil
+}
+
+type mockHookPlugin struct {
+	LC sen.Lifecycle `inject:"lifecycle"`
+}
+
+func (p *mockHookPlugin) Initialize() error {
+	p.LC.OnShutdown(func(_ context.Context) error {
+		return errors.New("shutdown error")
+	}, sen.WithHookName("close"))
+	return nil
+}
+
+func TestLifecycle_Observe(t *testing.T) {
+	t.Run("should send events when hooks are executed", func(t *testing.T) {
+		observer := &mockObserver{}
+		lc := sen.NewLifecycle()
+		lc.Observe(observer.observe)
+		lc.OnStart(func(_ context.Context) error {
+			time.Sleep(time.Millisecond)
+			return nil
+		}, sen.WithHookName("warm-up"))
+
+		lc.OnShutdown(func(_ context.Context) error {
+			return errors.New("shutdown error")
+		}, sen.WithHookName("db"))
+
+		err := lc.Run(context.Background())
+		if fmt.Sprintf("%v", err) != "shutdown hook db: shutdown error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		expected := "[start:warm-up:started start:warm-up:finished shutdown:db:started shutdown:db:failed]"
+		if fmt.Sprint(observer.summary()) != expected {
+			t.Errorf("Unexpected events %v", observer.summary())
+		}
+
+		if observer.events[1].Duration < time.Millisecond {
+			t.Errorf("Unexpected duration %v", observer.events[1].Duration)
+		}
+
+		if fmt.Sprintf("%v", observer.events[3].Err) != "shutdown error" {
+			t.Errorf("Unexpected err %v", observer.events[3].Err)
+		}
+	})
+
+	t.Run("should report the plugin owning a hook", func(t *testing.T) {
+		observer := &mockObserver{}
+		app := sen.New()
+		err := app.With(
+			&mockObserverPlugin{observer: observer},
+			&mockHookPlugin{},
+			sen.OnRun(func(_ context.Context) error {
+				return nil
+			}),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err = app.Run(context.Background())
+		if fmt.Sprintf("%v", err) != "shutdown hook close of *sen_test.mockHookPlugin: shutdown error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if len(observer.events) != 4 {
+			t.Errorf("Unexpected events %v", observer.summary())
+		}
+
+		for _, e := range observer.events {
+			if e.Phase == sen.PhaseShutdown && e.Owner != "*sen_test.mockHookPlugin" {
+				t.Errorf("Unexpected owner %v", e.Owner)
+			}
+
+			if e.Phase == sen.PhaseRun && e.Owner != "" {
+				t.Errorf("Unexpected owner %v", e.Owner)
+			}
+		}
+	})
+}
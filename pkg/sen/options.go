@@ -0,0 +1,156 @@
+package sen
+
+import "time"
+
+// Lifetime defines how long a component created by a constructor lives.
+type Lifetime int
+
+const (
+	// Singleton components are constructed once and shared everywhere. It's the default lifetime.
+	Singleton Lifetime = iota
+	// Transient components are constructed for every injection or retrieval.
+	Transient
+	// Scoped components are constructed once per Scope, i.e. per Hub they are resolved from.
+	// They can only be resolved from a scope created via NewScope and singletons can't depend on them
+	// as they would share a scoped component across scopes.
+	Scoped
+)
+
+// String returns the name of the lifetime.
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Transient:
+		return "transient"
+	case Scoped:
+		return "scoped"
+	default:
+		return "unknown"
+	}
+}
+
+// RegisterOption customizes how a component is registered into a Hub.
+type RegisterOption func(opts *registerOptions)
+
+type registerOptions struct {
+	lifetime  Lifetime
+	primary   bool
+	qualifier string
+}
+
+func newRegisterOptions(opts []RegisterOption) *registerOptions {
+	options := &registerOptions{
+		lifetime: Singleton,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// WithLifetime specifies the lifetime of a component created by a constructor.
+//
+// # Usage
+//
+//	hub.RegisterConstructor("builder", newBuilder, sen.WithLifetime(sen.Transient))
+func WithLifetime(l Lifetime) RegisterOption {
+	return func(opts *registerOptions) {
+		opts.lifetime = l
+	}
+}
+
+// AsPrimary marks a component as the primary one for its type.
+// It's chosen when there are multiple components for a type-based injection.
+//
+// # Usage
+//
+//	hub.Register("primary-db", primaryDB, sen.AsPrimary())
+func AsPrimary() RegisterOption {
+	return func(opts *registerOptions) {
+		opts.primary = true
+	}
+}
+
+// WithQualifier specifies a qualifier of a component. It allows
+// picking the component among candidates via `inject:"*,qualifier=name"`.
+//
+// # Usage
+//
+//	hub.Register("replica-db", replicaDB, sen.WithQualifier("readonly"))
+func WithQualifier(qualifier string) RegisterOption {
+	return func(opts *registerOptions) {
+		opts.qualifier = qualifier
+	}
+}
+
+// HookOption customizes a lifecycle hook.
+type HookOption func(opts *hookOptions)
+
+type hookOptions struct {
+	name       string
+	owner      string
+	timeout    time.Duration
+	supervisor supervisorOptions
+}
+
+// WithHookName gives a hook a name so it can be identified in errors.
+func WithHookName(name string) HookOption {
+	return func(opts *hookOptions) {
+		opts.name = name
+	}
+}
+
+// WithHookOwner specifies the owner of a hook. By default, a hook is owned
+// by the plugin registering it.
+func WithHookOwner(owner string) HookOption {
+	return func(opts *hookOptions) {
+		opts.owner = owner
+	}
+}
+
+// WithHookTimeout limits how long a hook can take. If the hook doesn't return in time,
+// it's considered failed with ErrHookTimeout and the lifecycle moves on.
+//
+// # Usage
+//
+//	lc.OnShutdown(db.Close, sen.WithHookName("db"), sen.WithHookTimeout(5*time.Second))
+func WithHookTimeout(timeout time.Duration) HookOption {
+	return func(opts *hookOptions) {
+		opts.timeout = timeout
+	}
+}
+
+// WithRestartPolicy runs an OnRun hook in the supervisor mode. The hook is restarted
+// according to the policy instead of shutting down the application when it returns.
+// It has no effect on hooks of other phases.
+//
+// # Usage
+//
+//	lc.OnRun(worker.Run, sen.WithRestartPolicy(sen.RestartOnFailure), sen.WithMaxRestarts(5))
+func WithRestartPolicy(policy RestartPolicy) HookOption {
+	return func(opts *hookOptions) {
+		opts.supervisor.policy = policy
+	}
+}
+
+// WithMaxRestarts limits how many times in a row a supervised hook is restarted.
+// After that, the error of the hook is returned as usual. Zero means no limit.
+// The count is reset once the hook runs for at least the max backoff of WithRestartBackoff.
+func WithMaxRestarts(n int) HookOption {
+	return func(opts *hookOptions) {
+		opts.supervisor.maxRestarts = n
+	}
+}
+
+// WithRestartBackoff specifies how long to wait before restarting a supervised hook.
+// The delay starts with initial and is doubled after every restart until it reaches max.
+// It's reset to initial once the hook runs for at least max.
+func WithRestartBackoff(initial, max time.Duration) HookOption {
+	return func(opts *hookOptions) {
+		opts.supervisor.initialBackoff = initial
+		opts.supervisor.maxBackoff = max
+	}
+}
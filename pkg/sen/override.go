@@ -0,0 +1,211 @@
+package sen
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// decorator wraps a component. It returns an error if the component can't be wrapped.
+type decorator func(component interface{}) (interface{}, error)
+
+// Replace creates a plugin that replaces the component registered under name with component.
+// It's meant for tests to swap a real component for a fake after a Bundle is applied,
+// as registering a name twice is rejected otherwise.
+//
+// The plugin waits for the component to be registered and it's initialized before other
+// plugins depending on the component. Components injected before the replacement keep
+// the original component, so overrides should be applied together with or before their consumers.
+//
+// # Usage
+//
+//	app.With(
+//		postgresgorm.Bundle(),
+//		sen.Replace("gorm", fakeDB),
+//	)
+func Replace(name string, component interface{}) Plugin {
+	return &replacePlugin{
+		name:      name,
+		component: component,
+	}
+}
+
+type replacePlugin struct {
+	Hub Hub `inject:"hub"`
+
+	name      string
+	component interface{}
+}
+
+// Initialize replaces the component in the hub.
+func (p *replacePlugin) Initialize() error {
+	hub, ok := p.Hub.(*defaultHub)
+	if !ok {
+		return fmt.Errorf("sen: unable to replace %s: overrides can only be applied via Application.With", p.name)
+	}
+
+	return hub.replace(p.name, p.component)
+}
+
+func (p *replacePlugin) overrides() string {
+	return p.name
+}
+
+// Decorate creates a plugin that wraps the component registered under name via fn,
+// e.g. to wrap a client with tracing. Lazy components are wrapped once they are constructed.
+// The component must be a T, otherwise *TypeMismatchError is returned.
+// Like Replace, it waits for the component and takes priority over consumers of the component.
+//
+// # Usage
+//
+//	app.With(sen.Decorate[Client]("client", func(c Client) Client {
+//		return &tracingClient{Client: c}
+//	}))
+func Decorate[T any](name string, fn func(T) T) Plugin {
+	return &decoratePlugin{
+		name: name,
+		typ:  reflect.TypeOf((*T)(nil)).Elem(),
+		fn: func(component interface{}) (interface{}, error) {
+			typedComponent, ok := component.(T)
+			if !ok {
+				return nil, &TypeMismatchError{
+					Name:     name,
+					Expected: reflect.TypeOf((*T)(nil)).Elem(),
+					Actual:   reflect.TypeOf(component),
+				}
+			}
+
+			return fn(typedComponent), nil
+		},
+	}
+}
+
+type decoratePlugin struct {
+	Hub Hub `inject:"hub"`
+
+	name string
+	typ  reflect.Type
+	fn   decorator
+}
+
+// Initialize decorates the component in the hub.
+func (p *decoratePlugin) Initialize() error {
+	hub, ok := p.Hub.(*defaultHub)
+	if !ok {
+		return fmt.Errorf("sen: unable to decorate %s: overrides can only be applied via Application.With", p.name)
+	}
+
+	return hub.decorate(p.name, p.typ, p.fn)
+}
+
+func (p *decoratePlugin) overrides() string {
+	return p.name
+}
+
+// overridable finds the dependency to be overridden. Exported components are overridden
+// in the module they are registered to.
+func (hub *defaultHub) overridable(name string) (*dependency, error) {
+	dep, found := hub.lookup(name)
+	if !found {
+		return nil, errNotRegistered(name)
+	}
+
+	for dep.target != nil {
+		dep = dep.target
+	}
+
+	dep.owner.mu.RLock()
+	sealed := dep.owner.sealed
+	dep.owner.mu.RUnlock()
+	if sealed {
+		return nil, fmt.Errorf("hub: unable to override %s: %w", name, ErrHubSealed)
+	}
+
+	return dep, nil
+}
+
+// replace replaces a registered component in place so its position in the registration
+// and instantiation order is kept.
+func (hub *defaultHub) replace(name string, component interface{}) error {
+	dep, err := hub.overridable(name)
+	if err != nil {
+		return err
+	}
+
+	replacement := &dependency{
+		name:         name,
+		value:        component,
+		reflectType:  reflect.TypeOf(component),
+		reflectValue: reflect.ValueOf(component),
+	}
+
+	if err := dep.owner.inject(replacement, &resolution{}); err != nil {
+		return err
+	}
+
+	dep.mu.Lock()
+	wasConstructed := dep.constructed && dep.lifetime == Singleton
+	dep.value = replacement.value
+	dep.reflectType = replacement.reflectType
+	dep.reflectValue = replacement.reflectValue
+	dep.constructor = reflect.Value{}
+	dep.lifetime = Singleton
+	dep.constructed = true
+	dep.decorators = nil
+	dep.mu.Unlock()
+
+	if !wasConstructed {
+		dep.owner.addInstance(dep)
+	}
+
+	return nil
+}
+
+// decorate wraps a registered component via fn. Components which aren't constructed yet
+// are wrapped every time they are constructed.
+func (hub *defaultHub) decorate(name string, typ reflect.Type, fn decorator) error {
+	dep, err := hub.overridable(name)
+	if err != nil {
+		return err
+	}
+
+	dep.mu.Lock()
+	defer dep.mu.Unlock()
+
+	if dep.reflectType == nil || !dep.reflectType.AssignableTo(typ) {
+		return &TypeMismatchError{
+			Name:     name,
+			Expected: typ,
+			Actual:   dep.reflectType,
+		}
+	}
+
+	if dep.constructor.IsValid() && !(dep.lifetime == Singleton && dep.constructed) {
+		dep.decorators = append(dep.decorators, fn)
+		dep.reflectType = typ
+		return nil
+	}
+
+	component, err := fn(dep.value)
+	if err != nil {
+		return err
+	}
+
+	dep.value = component
+	dep.reflectType = reflect.TypeOf(component)
+	dep.reflectValue = reflect.ValueOf(component)
+	return nil
+}
+
+// applyDecorators wraps a constructed component with decorators of the dependency.
+func applyDecorators(dep *dependency, v reflect.Value) (reflect.Value, error) {
+	for _, fn := range dep.decorators {
+		component, err := fn(v.Interface())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v = reflect.ValueOf(component)
+	}
+
+	return v, nil
+}
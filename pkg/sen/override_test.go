@@ -0,0 +1,180 @@
+package sen_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+type mockGreeter interface {
+	Greet() string
+}
+
+type mockEnglishGreeter struct{}
+
+func (mockEnglishGreeter) Greet() string {
+	return "hello"
+}
+
+type mockLoudGreeter struct {
+	mockGreeter
+}
+
+func (g mockLoudGreeter) Greet() string {
+	return strings.ToUpper(g.mockGreeter.Greet())
+}
+
+type mockGreeterConsumer struct {
+	Greeter mockGreeter `inject:"greeter"`
+}
+
+type mockStoppableData struct {
+	stopped bool
+}
+
+func (d *mockStoppableData) Stop(_ context.Context) error {
+	d.stopped = true
+	return nil
+}
+
+func TestReplace(t *testing.T) {
+	t.Run("should replace a component registered by a previous plugin", func(t *testing.T) {
+		component := &mockComponent{}
+		app := sen.New()
+		err := app.With(sen.Bundle(sen.Component("data", 10)))
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err = app.With(
+			sen.Component("need-data", component),
+			sen.Replace("data", 20),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if component.Data != 20 {
+			t.Errorf("Unexpected data %v", component.Data)
+		}
+	})
+
+	t.Run("should wait for the component and take priority over consumers", func(t *testing.T) {
+		component := &mockComponent{}
+		app := sen.New()
+		err := app.With(
+			sen.Replace("data", 20),
+			sen.Component("need-data", component),
+			sen.Constructor("data", func() int { return 10 }),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if component.Data != 20 {
+			t.Errorf("Unexpected data %v", component.Data)
+		}
+	})
+
+	t.Run("should attach the replacement to the lifecycle instead", func(t *testing.T) {
+		original := &mockStoppableData{}
+		fake := &mockStoppableData{}
+		app := sen.New()
+		err := app.With(
+			sen.Component("data", original),
+			sen.Replace("data", fake),
+			sen.OnRun(func(_ context.Context) error { return nil }),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if original.stopped || !fake.stopped {
+			t.Errorf("Unexpected stopped components %v %v", original.stopped, fake.stopped)
+		}
+	})
+
+	t.Run("should return error if the component is never registered", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(sen.Replace("data", 20))
+		if !errors.Is(err, sen.ErrComponentNotRegistered) {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should still reject registering a name twice", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			sen.Component("data", 10),
+			sen.Component("data", 20),
+		)
+		if err == nil {
+			t.Errorf("Expected an error")
+		}
+	})
+}
+
+func TestDecorate(t *testing.T) {
+	decorator := func(g mockGreeter) mockGreeter {
+		return mockLoudGreeter{g}
+	}
+
+	t.Run("should wrap a registered component", func(t *testing.T) {
+		consumer := &mockGreeterConsumer{}
+		app := sen.New()
+		err := app.With(
+			sen.Component("consumer", consumer),
+			sen.Component("greeter", mockEnglishGreeter{}),
+			sen.Decorate[mockGreeter]("greeter", decorator),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if consumer.Greeter.Greet() != "HELLO" {
+			t.Errorf("Unexpected greeting %v", consumer.Greeter.Greet())
+		}
+	})
+
+	t.Run("should wrap a lazy component once it's constructed", func(t *testing.T) {
+		consumer := &mockGreeterConsumer{}
+		app := sen.New()
+		err := app.With(
+			sen.Constructor("greeter", func() mockEnglishGreeter { return mockEnglishGreeter{} }),
+			sen.Decorate[mockGreeter]("greeter", decorator),
+			sen.Decorate[mockGreeter]("greeter", decorator),
+			sen.Component("consumer", consumer),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if consumer.Greeter.Greet() != "HELLO" {
+			t.Errorf("Unexpected greeting %v", consumer.Greeter.Greet())
+		}
+
+		if _, ok := consumer.Greeter.(mockLoudGreeter).mockGreeter.(mockLoudGreeter); !ok {
+			t.Errorf("Unexpected greeter %#v", consumer.Greeter)
+		}
+	})
+
+	t.Run("should return error if the component isn't a T", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(
+			sen.Component("greeter", 10),
+			sen.Decorate[mockGreeter]("greeter", decorator),
+		)
+
+		var mismatchErr *sen.TypeMismatchError
+		if !errors.As(err, &mismatchErr) {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
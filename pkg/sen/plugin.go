@@ -5,20 +5,65 @@ type componentPlugin struct {
 
 	name      string
 	component any
+	opts      []RegisterOption
 }
 
 // Initialize adds the component to the application as a named dependency.
 func (p *componentPlugin) Initialize() error {
-	return p.Hub.Register(p.name, p.component)
+	return p.Hub.Register(p.name, p.component, p.opts...)
+}
+
+func (p *componentPlugin) provides() []string {
+	return []string{p.name}
+}
+
+func (p *componentPlugin) dependsOn() []interface{} {
+	return []interface{}{p.component}
 }
 
 // Component creates a new component plugin.
 // The simple plugin adds a component into the application
 // under the given name.
-func Component(name string, c any) Plugin {
+func Component(name string, c any, opts ...RegisterOption) Plugin {
 	return &componentPlugin{
 		name:      name,
 		component: c,
+		opts:      opts,
+	}
+}
+
+type constructorPlugin struct {
+	Hub Hub `inject:"hub"`
+
+	name        string
+	constructor any
+	opts        []RegisterOption
+}
+
+// Initialize adds the constructor to the application as a lazy named dependency.
+func (p *constructorPlugin) Initialize() error {
+	return p.Hub.RegisterConstructor(p.name, p.constructor, p.opts...)
+}
+
+func (p *constructorPlugin) provides() []string {
+	return []string{p.name}
+}
+
+// Constructor creates a new plugin that registers a constructor
+// under the given name. The component will be constructed when
+// it's injected or retrieved for the first time.
+// The lifetime of the component can be specified via WithLifetime.
+//
+// # Usage
+//
+//	app.With(sen.Constructor("db", func(cfg *Config) (*sql.DB, error) {
+//		return sql.Open("postgres", cfg.DSN)
+//	}))
+func Constructor(name string, constructor any, opts ...RegisterOption) Plugin {
+	return &constructorPlugin{
+		name:        name,
+		constructor: constructor,
+		opts:        opts,
 	}
 }
 
@@ -39,6 +84,27 @@ func (m bundlePlugin) Initialize() error {
 	return m.App.With(m.plugins...)
 }
 
+// OnStart adds multiple hooks to prepare the application before it runs.
+// The hooks are executed one by one in the given order.
+func OnStart(hooks ...Hook) Plugin {
+	return &onStartPlugin{
+		hooks: hooks,
+	}
+}
+
+type onStartPlugin struct {
+	LC    Lifecycle `inject:"lifecycle"`
+	hooks []Hook
+}
+
+// Initialize adds the hook to the application lifecycle.
+func (p onStartPlugin) Initialize() error {
+	for _, h := range p.hooks {
+		p.LC.OnStart(h)
+	}
+	return nil
+}
+
 // OnRun adds multiple hooks to run with the application.
 func OnRun(hooks ...Hook) Plugin {
 	return &onRunPlugin{
@@ -79,6 +145,26 @@ func (p onShutdownPlugin) Initialize() error {
 	return nil
 }
 
+// OnReload adds multiple hooks to reload the application without restarting it.
+func OnReload(hooks ...Hook) Plugin {
+	return &onReloadPlugin{
+		hooks: hooks,
+	}
+}
+
+type onReloadPlugin struct {
+	LC    Lifecycle `inject:"lifecycle"`
+	hooks []Hook
+}
+
+// Initialize adds the hook to the application lifecycle.
+func (p onReloadPlugin) Initialize() error {
+	for _, h := range p.hooks {
+		p.LC.OnReload(h)
+	}
+	return nil
+}
+
 // PostRun adds additional logic after all services stop running
 // and shutdown logic is executed.
 // It's useful for syncing logs, etc.
@@ -100,3 +186,23 @@ func (p postRunPlugin) Initialize() error {
 	}
 	return nil
 }
+
+// hookOwner returns the name of a plugin as the owner of hooks it registers.
+// Plugins only adding hooks don't own them as they just pass hooks through.
+func hookOwner(p Plugin) string {
+	if isHookPlugin(p) {
+		return ""
+	}
+
+	return pluginName(p)
+}
+
+// isHookPlugin returns whether the plugin only adds hooks to the lifecycle.
+func isHookPlugin(p Plugin) bool {
+	switch p.(type) {
+	case *onStartPlugin, *onRunPlugin, *onShutdownPlugin, *postRunPlugin, *onReloadPlugin:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,13 +1,14 @@
 package sen_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/bongnv/sen/pkg/sen"
 )
 
-const dataInjectErrMsg = "hub: data is not registered"
+const dataInjectErrMsg = "sen: unable to resolve dependencies\n\thub: data is not registered (injecting *sen_test.mockComponent.Data in %s)"
 
 type mockPlugin struct {
 	Data int `inject:"data"`
@@ -37,7 +38,7 @@ func TestComponent(t *testing.T) {
 		component := &mockComponent{}
 		app := sen.New()
 		err := app.With(sen.Component("need-data", component))
-		if fmt.Sprintf("%v", err) != dataInjectErrMsg {
+		if fmt.Sprintf("%v", err) != fmt.Sprintf(dataInjectErrMsg, "need-data") {
 			t.Errorf("Unexpected err %v", err)
 		}
 	})
@@ -67,7 +68,7 @@ func TestBundle(t *testing.T) {
 		)
 		app := sen.New()
 		err := app.With(m)
-		if fmt.Sprintf("%v", err) != dataInjectErrMsg {
+		if fmt.Sprintf("%v", err) != fmt.Sprintf(dataInjectErrMsg, "error-plugin") {
 			t.Errorf("Unexpected err %v", err)
 		}
 	})
@@ -79,8 +80,25 @@ func TestBundle(t *testing.T) {
 
 		app := sen.New()
 		err := app.With(m)
-		if fmt.Sprintf("%v", err) != dataInjectErrMsg {
+		if !errors.Is(err, sen.ErrComponentNotRegistered) {
 			t.Errorf("Unexpected err %v", err)
 		}
 	})
 }
+
+func TestConstructor(t *testing.T) {
+	t.Run("should register a constructor into the application", func(t *testing.T) {
+		component := &mockComponent{}
+		app := sen.New()
+		err := app.With(
+			sen.Constructor("data", func() int { return 10 }),
+			sen.Component("need-data", component),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+		if component.Data != 10 {
+			t.Errorf("Unexpected data %v", component.Data)
+		}
+	})
+}
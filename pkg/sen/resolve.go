@@ -0,0 +1,86 @@
+package sen
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// TypeMismatchError is returned when a component is found but its type
+// doesn't match the expected one.
+type TypeMismatchError struct {
+	Name     string
+	Expected reflect.Type
+	Actual   reflect.Type
+}
+
+// Error implements the error interface.
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("hub: %s is %s, not %s", e.Name, typeString(e.Actual), typeString(e.Expected))
+}
+
+// Provide creates a plugin that registers a component of type T
+// into the application under the given name.
+// It's a type-safe version of Component.
+//
+// # Usage
+//
+//	app.With(sen.Provide[*Config]("config", &Config{}))
+func Provide[T any](name string, component T, opts ...RegisterOption) Plugin {
+	return &componentPlugin{
+		name:      name,
+		component: component,
+		opts:      opts,
+	}
+}
+
+// Resolve retrieves the only component that is assignable to T from the hub.
+// It returns an error if there is no such component or there is more than one.
+func Resolve[T any](hub Hub) (T, error) {
+	target := &struct {
+		Component T `inject:"*"`
+	}{}
+
+	if err := hub.Inject(target); err != nil {
+		var zero T
+		var injectionErr *InjectionError
+		if errors.As(err, &injectionErr) {
+			// the target is internal so the underlying error is more useful.
+			err = injectionErr.Err
+		}
+
+		return zero, err
+	}
+
+	return target.Component, nil
+}
+
+// ResolveNamed retrieves a component via name and returns it as T.
+// It returns ErrComponentNotRegistered if the component isn't registered
+// and *TypeMismatchError if the component isn't a T.
+func ResolveNamed[T any](hub Hub, name string) (T, error) {
+	var zero T
+	component, err := hub.Retrieve(name)
+	if err != nil {
+		return zero, err
+	}
+
+	typedComponent, ok := component.(T)
+	if !ok {
+		return zero, &TypeMismatchError{
+			Name:     name,
+			Expected: reflect.TypeOf((*T)(nil)).Elem(),
+			Actual:   reflect.TypeOf(component),
+		}
+	}
+
+	return typedComponent, nil
+}
+
+func typeString(t reflect.Type) string {
+	if t == nil {
+		return "nil"
+	}
+
+	return t.String()
+}
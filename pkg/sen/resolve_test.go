@@ -0,0 +1,113 @@
+package sen_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+type mockNamer interface {
+	Name() string
+}
+
+type mockNamerImpl struct {
+	name string
+}
+
+func (m *mockNamerImpl) Name() string {
+	return m.name
+}
+
+func TestProvide(t *testing.T) {
+	t.Run("should register a typed component into the application", func(t *testing.T) {
+		component := &mockComponent{}
+		app := sen.New()
+		err := app.With(
+			sen.Provide("data", 10),
+			sen.Provide("need-data", component),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if component.Data != 10 {
+			t.Errorf("Unexpected data %v", component.Data)
+		}
+	})
+}
+
+func TestResolve(t *testing.T) {
+	t.Run("should return the component by its type", func(t *testing.T) {
+		hub := sen.NewHub()
+		namer := &mockNamerImpl{name: "namer"}
+		if err := hub.Register("namer", namer); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		loaded, err := sen.Resolve[mockNamer](hub)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if loaded != namer {
+			t.Errorf("Unexpected component %v", loaded)
+		}
+	})
+
+	t.Run("should return an error if there is no suitable component", func(t *testing.T) {
+		hub := sen.NewHub()
+		loaded, err := sen.Resolve[mockNamer](hub)
+		if fmt.Sprintf("%v", err) != "hub: couldn't find the dependency for sen_test.mockNamer" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if loaded != nil {
+			t.Errorf("Expected nil but got %v", loaded)
+		}
+	})
+}
+
+func TestResolveNamed(t *testing.T) {
+	t.Run("should return the typed component if it's registered", func(t *testing.T) {
+		hub := sen.NewHub()
+		if err := hub.Register("data", 10); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		data, err := sen.ResolveNamed[int](hub, "data")
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if data != 10 {
+			t.Errorf("Unexpected data: %v", data)
+		}
+	})
+
+	t.Run("should return an error if the component isn't registered", func(t *testing.T) {
+		hub := sen.NewHub()
+		_, err := sen.ResolveNamed[int](hub, "data")
+		if !errors.Is(err, sen.ErrComponentNotRegistered) {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should return an error if the type doesn't match", func(t *testing.T) {
+		hub := sen.NewHub()
+		if err := hub.Register("data", 10); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		_, err := sen.ResolveNamed[string](hub, "data")
+		var mismatchErr *sen.TypeMismatchError
+		if !errors.As(err, &mismatchErr) {
+			t.Fatalf("Expected TypeMismatchError but got %v", err)
+		}
+
+		if err.Error() != "hub: data is int, not string" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
@@ -0,0 +1,186 @@
+// Package senttest provides utilities for testing sen applications.
+//
+// # Usage
+//
+//	func TestHandler(t *testing.T) {
+//		app := senttest.New(t, echo.Bundle(), sen.Replace("gorm", fakeDB))
+//		e := senttest.ResolveNamed[*echo.Echo](app, "echo")
+//		// send requests to e...
+//	}
+package senttest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+const (
+	defaultStartTimeout    = 10 * time.Second
+	defaultShutdownTimeout = 10 * time.Second
+)
+
+type options struct {
+	startTimeout    time.Duration
+	shutdownTimeout time.Duration
+}
+
+// Option customizes an application created via NewWithOptions.
+type Option func(o *options)
+
+// WithStartTimeout bounds how long OnStart hooks of the application may take.
+// It's 10 seconds by default.
+func WithStartTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.startTimeout = timeout
+	}
+}
+
+// WithShutdownTimeout bounds how long the application may take to shut down.
+// It's 10 seconds by default.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// App is an application running in the background of a test.
+type App struct {
+	*sen.Application
+
+	tb   testing.TB
+	opts options
+	hub  sen.Hub
+	lc   sen.Lifecycle
+	stop chan struct{}
+	done chan error
+}
+
+// New creates an application from plugins and runs it in the background.
+// It returns once all OnStart hooks succeed and the application is shut down
+// when the test and its subtests complete. The test fails immediately
+// if the plugins can't be applied or the application can't start.
+func New(tb testing.TB, plugins ...sen.Plugin) *App {
+	tb.Helper()
+	return NewWithOptions(tb, nil, plugins...)
+}
+
+// NewWithOptions creates an application like New but customized by options.
+//
+// # Usage
+//
+//	app := senttest.NewWithOptions(t, []senttest.Option{
+//		senttest.WithStartTimeout(time.Second),
+//	}, echo.Bundle())
+func NewWithOptions(tb testing.TB, opts []Option, plugins ...sen.Plugin) *App {
+	tb.Helper()
+
+	options := options{
+		startTimeout:    defaultStartTimeout,
+		shutdownTimeout: defaultShutdownTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	app := &App{
+		Application: sen.New(sen.StartTimeout(options.startTimeout)),
+		tb:          tb,
+		opts:        options,
+		stop:        make(chan struct{}),
+		done:        make(chan error, 1),
+	}
+
+	if err := app.With(append(plugins, &testPlugin{app: app})...); err != nil {
+		tb.Fatalf("senttest: unable to apply plugins: %v", err)
+	}
+
+	go func() {
+		app.done <- app.Run(context.Background())
+	}()
+
+	select {
+	case <-app.lc.Ready():
+	case err := <-app.done:
+		tb.Fatalf("senttest: the application is %s before it's ready: %v", app.State(), err)
+	}
+
+	tb.Cleanup(app.shutdown)
+	return app
+}
+
+// Hub returns the hub of the application.
+func (app *App) Hub() sen.Hub {
+	return app.hub
+}
+
+// shutdown shuts the application down and fails the test if it doesn't stop gracefully.
+func (app *App) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), app.opts.shutdownTimeout)
+	defer cancel()
+
+	// errors of shutdown hooks are also returned by Run.
+	_ = app.Shutdown(ctx)
+	select {
+	case err := <-app.done:
+		if err != nil {
+			app.tb.Errorf("senttest: the application stopped with errors: %v", err)
+		}
+	case <-ctx.Done():
+		app.tb.Errorf("senttest: the application didn't stop after %s", app.opts.shutdownTimeout)
+	}
+}
+
+// Resolve retrieves the only component that is assignable to T from the application.
+// The test fails immediately if there is no such component or there is more than one.
+func Resolve[T any](app *App) T {
+	app.tb.Helper()
+
+	component, err := sen.Resolve[T](app.hub)
+	if err != nil {
+		app.tb.Fatalf("senttest: unable to resolve %s: %v", reflect.TypeOf((*T)(nil)).Elem(), err)
+	}
+
+	return component
+}
+
+// ResolveNamed retrieves a component via name and returns it as T.
+// The test fails immediately if the component isn't registered or it isn't a T.
+func ResolveNamed[T any](app *App, name string) T {
+	app.tb.Helper()
+
+	component, err := sen.ResolveNamed[T](app.hub, name)
+	if err != nil {
+		app.tb.Fatalf("senttest: unable to resolve %s: %v", name, err)
+	}
+
+	return component
+}
+
+// testPlugin keeps the application running until it's shut down.
+type testPlugin struct {
+	Hub sen.Hub       `inject:"hub"`
+	LC  sen.Lifecycle `inject:"lifecycle"`
+
+	app *App
+}
+
+// Initialize adds hooks to keep the application running.
+func (p *testPlugin) Initialize() error {
+	p.app.hub = p.Hub
+	p.app.lc = p.LC
+	p.LC.OnRun(func(_ context.Context) error {
+		<-p.app.stop
+		return nil
+	}, sen.WithHookName("senttest"))
+	p.LC.OnShutdown(func(_ context.Context) error {
+		close(p.app.stop)
+		return nil
+	}, sen.WithHookName("senttest"))
+
+	return nil
+}
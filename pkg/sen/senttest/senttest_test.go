@@ -0,0 +1,188 @@
+package senttest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bongnv/sen/pkg/sen"
+	"github.com/bongnv/sen/pkg/sen/senttest"
+)
+
+type mockServer struct {
+	started bool
+	stopped bool
+}
+
+func (s *mockServer) Start(_ context.Context) error {
+	s.started = true
+	return nil
+}
+
+func (s *mockServer) Stop(_ context.Context) error {
+	s.stopped = true
+	return nil
+}
+
+type mockNeedData struct {
+	Data int `inject:"data"`
+}
+
+// mockTB records failures instead of failing the test.
+type mockTB struct {
+	testing.TB
+	failures []string
+	cleanups []func()
+}
+
+func (tb *mockTB) Helper() {}
+
+func (tb *mockTB) Errorf(format string, args ...interface{}) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+func (tb *mockTB) Fatalf(format string, args ...interface{}) {
+	tb.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func (tb *mockTB) Cleanup(fn func()) {
+	tb.cleanups = append(tb.cleanups, fn)
+}
+
+func (tb *mockTB) runCleanups() {
+	for i := len(tb.cleanups) - 1; i >= 0; i-- {
+		tb.cleanups[i]()
+	}
+}
+
+// run runs fn like a test and returns failures.
+func run(t *testing.T, fn func(tb testing.TB)) []string {
+	tb := &mockTB{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(tb)
+	}()
+	<-done
+	tb.runCleanups()
+	return tb.failures
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should run the application until the test completes", func(t *testing.T) {
+		server := &mockServer{}
+		failures := run(t, func(tb testing.TB) {
+			app := senttest.New(tb, sen.Component("server", server))
+			if !server.started {
+				t.Errorf("The server should be started")
+			}
+
+			if app.State() != sen.StateRunning {
+				t.Errorf("Unexpected state %v", app.State())
+			}
+		})
+		if len(failures) > 0 {
+			t.Errorf("Unexpected failures %v", failures)
+		}
+
+		if !server.stopped {
+			t.Errorf("The server should be stopped")
+		}
+	})
+
+	t.Run("should fail with diagnostics if plugins can't be applied", func(t *testing.T) {
+		failures := run(t, func(tb testing.TB) {
+			senttest.New(tb, sen.Component("need-data", &mockNeedData{}))
+			t.Errorf("New should fail the test")
+		})
+
+		if len(failures) != 1 || !strings.Contains(failures[0], "hub: data is not registered") {
+			t.Errorf("Unexpected failures %v", failures)
+		}
+	})
+
+	t.Run("should fail if the application can't start", func(t *testing.T) {
+		failures := run(t, func(tb testing.TB) {
+			senttest.New(tb, sen.OnStart(func(_ context.Context) error {
+				return errors.New("start error")
+			}))
+			t.Errorf("New should fail the test")
+		})
+
+		if len(failures) != 1 || !strings.Contains(failures[0], "start error") {
+			t.Errorf("Unexpected failures %v", failures)
+		}
+	})
+
+	t.Run("should fail if OnStart hooks exceed the start timeout", func(t *testing.T) {
+		failures := run(t, func(tb testing.TB) {
+			senttest.NewWithOptions(tb, []senttest.Option{senttest.WithStartTimeout(10 * time.Millisecond)},
+				sen.OnStart(func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				}),
+			)
+			t.Errorf("New should fail the test")
+		})
+
+		if len(failures) != 1 || !strings.Contains(failures[0], "context deadline exceeded") {
+			t.Errorf("Unexpected failures %v", failures)
+		}
+	})
+
+	t.Run("should report errors while shutting down", func(t *testing.T) {
+		failures := run(t, func(tb testing.TB) {
+			senttest.New(tb, sen.OnShutdown(func(_ context.Context) error {
+				return errors.New("shutdown error")
+			}))
+		})
+
+		if len(failures) != 1 || !strings.Contains(failures[0], "shutdown error") {
+			t.Errorf("Unexpected failures %v", failures)
+		}
+	})
+}
+
+func TestResolve(t *testing.T) {
+	t.Run("should resolve components by types and names", func(t *testing.T) {
+		server := &mockServer{}
+		app := senttest.New(t, sen.Component("server", server), sen.Component("data", 10))
+		if senttest.Resolve[*mockServer](app) != server {
+			t.Errorf("Unexpected server")
+		}
+
+		if data := senttest.ResolveNamed[int](app, "data"); data != 10 {
+			t.Errorf("Unexpected data %v", data)
+		}
+	})
+
+	t.Run("should fail if the component can't be resolved", func(t *testing.T) {
+		failures := run(t, func(tb testing.TB) {
+			app := senttest.New(tb, sen.Component("data", 10))
+			senttest.ResolveNamed[string](app, "data")
+			t.Errorf("ResolveNamed should fail the test")
+		})
+
+		if len(failures) != 1 || !strings.Contains(failures[0], "hub: data is int, not string") {
+			t.Errorf("Unexpected failures %v", failures)
+		}
+	})
+
+	t.Run("should name the type if no component is found by type", func(t *testing.T) {
+		failures := run(t, func(tb testing.TB) {
+			app := senttest.New(tb)
+			senttest.Resolve[io.Reader](app)
+			t.Errorf("Resolve should fail the test")
+		})
+
+		if len(failures) != 1 || !strings.HasPrefix(failures[0], "senttest: unable to resolve io.Reader: ") {
+			t.Errorf("Unexpected failures %v", failures)
+		}
+	})
+}
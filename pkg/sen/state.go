@@ -0,0 +1,155 @@
+package sen
+
+import (
+	"fmt"
+)
+
+// State represents a state of an application.
+type State int
+
+const (
+	// StateCreated is the state of a newly created application.
+	StateCreated State = iota
+	// StateInitializing is the state while plugins are being applied via With.
+	StateInitializing
+	// StateStarting is the state while OnStart hooks are being executed.
+	StateStarting
+	// StateRunning is the state after all OnStart hooks succeed.
+	StateRunning
+	// StateStopping is the state while the application is shutting down.
+	StateStopping
+	// StateStopped is the state after the application stops without any error.
+	StateStopped
+	// StateFailed is the state after the application fails to initialize or to run.
+	StateFailed
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateCreated:
+		return "created"
+	case StateInitializing:
+		return "initializing"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// isTerminal returns whether the state is the last one of an application.
+func (s State) isTerminal() bool {
+	return s == StateStopped || s == StateFailed
+}
+
+// State returns the current state of the application.
+func (app *Application) State() State {
+	app.stateMu.Lock()
+	defer app.stateMu.Unlock()
+	return app.state
+}
+
+// Subscribe returns a channel receiving states of the application whenever it changes.
+// The channel is closed after the application is stopped or failed.
+// It's closed immediately if the application is already stopped or failed.
+//
+// # Usage
+//
+//	for state := range app.Subscribe() {
+//		log.Println("application is", state)
+//	}
+func (app *Application) Subscribe() <-chan State {
+	app.stateMu.Lock()
+	defer app.stateMu.Unlock()
+
+	// the channel is big enough to hold all remaining states so sending never blocks.
+	ch := make(chan State, int(StateFailed)+1)
+	if app.state.isTerminal() {
+		close(ch)
+		return ch
+	}
+
+	app.subscribers = append(app.subscribers, ch)
+	return ch
+}
+
+// transition moves the application to the given state if its current state is one of from.
+// It returns ErrInvalidState otherwise.
+func (app *Application) transition(op string, to State, from ...State) error {
+	app.stateMu.Lock()
+	defer app.stateMu.Unlock()
+
+	for _, s := range from {
+		if app.state == s {
+			app.setStateLocked(to)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("sen: unable to %s the application while it's %s: %w", op, app.state, ErrInvalidState)
+}
+
+// require returns ErrInvalidState if the current state of the application isn't one of states.
+func (app *Application) require(op string, states ...State) error {
+	app.stateMu.Lock()
+	defer app.stateMu.Unlock()
+
+	for _, s := range states {
+		if app.state == s {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("sen: unable to %s the application while it's %s: %w", op, app.state, ErrInvalidState)
+}
+
+func (app *Application) setState(s State) {
+	app.stateMu.Lock()
+	defer app.stateMu.Unlock()
+	app.setStateLocked(s)
+}
+
+func (app *Application) setStateLocked(s State) {
+	if app.state == s {
+		return
+	}
+
+	app.state = s
+	if s >= StateStarting {
+		select {
+		case <-app.started:
+		default:
+			close(app.started)
+		}
+	}
+
+	for _, ch := range app.subscribers {
+		ch <- s
+		if s.isTerminal() {
+			close(ch)
+		}
+	}
+
+	if s.isTerminal() {
+		app.subscribers = nil
+	}
+}
+
+// trackLifecycle moves the application through states according to phases of its lifecycle.
+func (app *Application) trackLifecycle(p Phase) {
+	switch p {
+	case PhaseRun:
+		_ = app.transition("run", StateRunning, StateStarting)
+	case PhaseShutdown:
+		_ = app.transition("shut down", StateStopping, StateStarting, StateRunning)
+	}
+}
@@ -0,0 +1,169 @@
+package sen_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+func TestApplication_State(t *testing.T) {
+	t.Run("should go through all states while running", func(t *testing.T) {
+		app := sen.New()
+		states := app.Subscribe()
+		err := app.With(sen.OnRun(func(_ context.Context) error {
+			if app.State() != sen.StateRunning {
+				t.Errorf("Unexpected state %v", app.State())
+			}
+			return nil
+		}))
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		var received []sen.State
+		for s := range states {
+			received = append(received, s)
+		}
+
+		if fmt.Sprint(received) != "[initializing starting running stopping stopped]" {
+			t.Errorf("Unexpected states %v", received)
+		}
+	})
+
+	t.Run("should be failed if running fails", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(sen.OnStart(func(_ context.Context) error {
+			return errors.New("start error")
+		}))
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		states := app.Subscribe()
+		_ = app.Run(context.Background())
+		var received []sen.State
+		for s := range states {
+			received = append(received, s)
+		}
+
+		if fmt.Sprint(received) != "[starting stopping failed]" {
+			t.Errorf("Unexpected states %v", received)
+		}
+	})
+
+	t.Run("should be failed if a plugin fails", func(t *testing.T) {
+		app := sen.New()
+		err := app.With(&mockPlugin{})
+		if err == nil {
+			t.Errorf("Expected an error")
+		}
+
+		if app.State() != sen.StateFailed {
+			t.Errorf("Unexpected state %v", app.State())
+		}
+
+		err = app.Run(context.Background())
+		if !errors.Is(err, sen.ErrInvalidState) {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should return an error if the application runs twice", func(t *testing.T) {
+		app := sen.New()
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err := app.Run(context.Background())
+		if fmt.Sprintf("%v", err) != "sen: unable to run the application while it's stopped: sen: invalid application state" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should return an error if plugins are applied after running", func(t *testing.T) {
+		app := sen.New()
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		err := app.With(sen.Component("data", 10))
+		if !errors.Is(err, sen.ErrInvalidState) {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should shut down the application once it runs if Shutdown is called before", func(t *testing.T) {
+		started := false
+		app := sen.New()
+		err := app.With(
+			makeMockWaitingPlugin(),
+			sen.OnStart(func(_ context.Context) error {
+				started = true
+				return nil
+			}),
+		)
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		shutdownErr := make(chan error)
+		go func() {
+			shutdownErr <- app.Shutdown(context.Background())
+		}()
+
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := <-shutdownErr; err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if !started {
+			t.Errorf("Expected the application is started")
+		}
+	})
+
+	t.Run("should return an error if the application doesn't run before the context is done", func(t *testing.T) {
+		app := sen.New()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := app.Shutdown(ctx)
+		if fmt.Sprintf("%v", err) != "sen: unable to shut down the application while it's created: context canceled" {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should return an error if the application reloads while it isn't running", func(t *testing.T) {
+		app := sen.New()
+		if err := app.Reload(context.Background()); !errors.Is(err, sen.ErrInvalidState) {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := app.Reload(context.Background()); !errors.Is(err, sen.ErrInvalidState) {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+
+	t.Run("should close the subscription immediately if the application is stopped", func(t *testing.T) {
+		app := sen.New()
+		if err := app.Run(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if _, ok := <-app.Subscribe(); ok {
+			t.Errorf("Expected the channel is closed")
+		}
+	})
+}
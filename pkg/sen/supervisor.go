@@ -0,0 +1,150 @@
+package sen
+
+import (
+	"context"
+	"time"
+)
+
+// RestartPolicy defines when a supervised OnRun hook is restarted.
+type RestartPolicy int
+
+const (
+	// RestartNever never restarts the hook. It's the default policy.
+	RestartNever RestartPolicy = iota
+	// RestartOnFailure restarts the hook when it returns an error or panics.
+	RestartOnFailure
+	// RestartAlways restarts the hook whenever it returns until the application shuts down.
+	RestartAlways
+)
+
+// String returns the name of the restart policy.
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartNever:
+		return "never"
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+type supervisorOptions struct {
+	policy         RestartPolicy
+	maxRestarts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// shouldRestart returns whether a hook returning err should be restarted.
+func (o supervisorOptions) shouldRestart(err error, restarts int) bool {
+	if o.maxRestarts > 0 && restarts >= o.maxRestarts {
+		return false
+	}
+
+	switch o.policy {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// backoffLimits returns the initial and max delays, falling back to defaults.
+func (o supervisorOptions) backoffLimits() (time.Duration, time.Duration) {
+	initial, max := o.initialBackoff, o.maxBackoff
+	if initial <= 0 {
+		initial = defaultInitialBackoff
+	}
+
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+
+	return initial, max
+}
+
+// backoff returns the delay before the next restart.
+func (o supervisorOptions) backoff(restarts int) time.Duration {
+	initial, max := o.backoffLimits()
+	delay := initial
+	for i := 0; i < restarts && delay < max; i++ {
+		delay *= 2
+	}
+
+	if delay > max {
+		return max
+	}
+
+	return delay
+}
+
+// isStable returns whether a hook running for d is considered stable,
+// i.e. it has run for at least the max backoff.
+func (o supervisorOptions) isStable(d time.Duration) bool {
+	_, max := o.backoffLimits()
+	return d >= max
+}
+
+// supervise executes a run hook and restarts it according to its restart policy
+// until the application shuts down. It returns the last error of the hook.
+// The restart count and the backoff are reset once the hook runs stably.
+func (lc *defaultLifecycle) supervise(ctx context.Context, h *lifecycleHook) error {
+	if h.phase != PhaseRun {
+		return lc.execute(ctx, h)
+	}
+
+	for restarts := 0; ; restarts++ {
+		startedAt := time.Now()
+		err := lc.execute(ctx, h)
+		if h.supervisor.isStable(time.Since(startedAt)) {
+			restarts = 0
+		}
+
+		if !h.supervisor.shouldRestart(err, restarts) || lc.isStopping(ctx) {
+			return err
+		}
+
+		delay := h.supervisor.backoff(restarts)
+		lc.notify(HookEvent{
+			Type:     HookRestarting,
+			Phase:    h.phase,
+			Name:     h.name,
+			Owner:    h.owner,
+			Duration: delay,
+			Err:      err,
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-lc.stopping:
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// isStopping returns whether the application is shutting down.
+func (lc *defaultLifecycle) isStopping(ctx context.Context) bool {
+	select {
+	case <-lc.stopping:
+		return true
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
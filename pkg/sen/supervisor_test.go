@@ -0,0 +1,189 @@
+package sen_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+func TestLifecycle_Supervisor(t *testing.T) {
+	t.Run("should restart a failed hook until it succeeds", func(t *testing.T) {
+		calls := 0
+		observer := &mockObserver{}
+		lc := sen.NewLifecycle()
+		lc.Observe(observer.observe)
+		lc.OnRun(func(_ context.Context) error {
+			calls++
+			if calls < 3 {
+				return errors.New("worker error")
+			}
+
+			return nil
+		}, sen.WithHookName("worker"), sen.WithRestartPolicy(sen.RestartOnFailure), sen.WithRestartBackoff(time.Millisecond, time.Millisecond))
+
+		err := lc.Run(context.Background())
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if calls != 3 {
+			t.Errorf("Expected the hook is called 3 times but got %d", calls)
+		}
+
+		restarts := 0
+		for _, e := range observer.events {
+			if e.Type == sen.HookRestarting {
+				restarts++
+			}
+		}
+
+		if restarts != 2 {
+			t.Errorf("Unexpected events %v", observer.summary())
+		}
+	})
+
+	t.Run("should return the error after reaching max restarts", func(t *testing.T) {
+		calls := 0
+		lc := sen.NewLifecycle()
+		lc.OnRun(func(_ context.Context) error {
+			calls++
+			return errors.New("worker error")
+		}, sen.WithRestartPolicy(sen.RestartOnFailure), sen.WithMaxRestarts(2), sen.WithRestartBackoff(time.Millisecond, time.Second))
+
+		err := lc.Run(context.Background())
+		if fmt.Sprintf("%v", err) != "run hook #0: worker error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if calls != 3 {
+			t.Errorf("Expected the hook is called 3 times but got %d", calls)
+		}
+	})
+
+	t.Run("should reset max restarts after the hook runs stably", func(t *testing.T) {
+		calls := 0
+		lc := sen.NewLifecycle()
+		lc.OnRun(func(_ context.Context) error {
+			calls++
+			if calls == 2 {
+				time.Sleep(50 * time.Millisecond)
+			}
+
+			return errors.New("worker error")
+		}, sen.WithRestartPolicy(sen.RestartOnFailure), sen.WithMaxRestarts(1), sen.WithRestartBackoff(time.Millisecond, 20*time.Millisecond))
+
+		err := lc.Run(context.Background())
+		if fmt.Sprintf("%v", err) != "run hook #0: worker error" {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if calls != 3 {
+			t.Errorf("Expected the hook is called 3 times but got %d", calls)
+		}
+	})
+
+	t.Run("should not restart a hook that succeeds with the on-failure policy", func(t *testing.T) {
+		calls := 0
+		lc := sen.NewLifecycle()
+		lc.OnRun(func(_ context.Context) error {
+			calls++
+			return nil
+		}, sen.WithRestartPolicy(sen.RestartOnFailure))
+
+		err := lc.Run(context.Background())
+		if err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if calls != 1 {
+			t.Errorf("Expected the hook is called once but got %d", calls)
+		}
+	})
+
+	t.Run("should keep restarting a hook with the always policy until shutdown", func(t *testing.T) {
+		calls := int32(0)
+		lc := sen.NewLifecycle()
+		lc.OnRun(func(_ context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		}, sen.WithRestartPolicy(sen.RestartAlways), sen.WithRestartBackoff(time.Millisecond, time.Millisecond))
+
+		doneCh := make(chan error)
+		go func() {
+			doneCh <- lc.Run(context.Background())
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		if err := lc.Shutdown(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		select {
+		case err := <-doneCh:
+			if err != nil {
+				t.Errorf("Unexpected err %v", err)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("test timed out")
+		}
+
+		if atomic.LoadInt32(&calls) < 2 {
+			t.Errorf("Expected the hook is restarted but got %d calls", calls)
+		}
+	})
+
+	t.Run("should keep other hooks running while a hook is restarted", func(t *testing.T) {
+		lc := sen.NewLifecycle()
+		stopCh := make(chan struct{})
+		serverCtx := make(chan context.Context, 1)
+		lc.OnRun(func(ctx context.Context) error {
+			serverCtx <- ctx
+			<-stopCh
+			return nil
+		})
+
+		lc.OnShutdown(func(_ context.Context) error {
+			close(stopCh)
+			return nil
+		})
+
+		workerCalls := make(chan struct{}, 2)
+		lc.OnRun(func(_ context.Context) error {
+			workerCalls <- struct{}{}
+			if len(workerCalls) == 1 {
+				panic("worker panic")
+			}
+
+			return nil
+		}, sen.WithRestartPolicy(sen.RestartOnFailure), sen.WithRestartBackoff(time.Millisecond, time.Millisecond))
+
+		doneCh := make(chan error)
+		go func() {
+			doneCh <- lc.Run(context.Background())
+		}()
+
+		ctx := <-serverCtx
+		select {
+		case err := <-doneCh:
+			t.Fatalf("Expected the lifecycle still runs but got %v", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		if ctx.Err() != nil || len(workerCalls) != 2 {
+			t.Errorf("Expected the worker is restarted without canceling the server")
+		}
+
+		if err := lc.Shutdown(context.Background()); err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+
+		if err := <-doneCh; err != nil {
+			t.Errorf("Unexpected err %v", err)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package sen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// version is a parsed semantic version without pre-release and build metadata.
+type version [3]int
+
+func parseVersion(s string) (version, error) {
+	var v version
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if s == "" || len(parts) > len(v) {
+		return v, fmt.Errorf("sen: %q is not a valid version", s)
+	}
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("sen: %q is not a valid version", s)
+		}
+
+		v[i] = n
+	}
+
+	return v, nil
+}
+
+func (v version) compare(other version) int {
+	for i := range v {
+		switch {
+		case v[i] < other[i]:
+			return -1
+		case v[i] > other[i]:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// satisfiesVersion checks whether the version satisfies the constraint.
+// A constraint is a comma-separated list of comparisons that must all hold,
+// e.g. ">=0.2.0, <1.0.0". A version without an operator must match exactly.
+func satisfiesVersion(ver, constraint string) (bool, error) {
+	v, err := parseVersion(ver)
+	if err != nil {
+		return false, err
+	}
+
+	for _, clause := range strings.Split(constraint, ",") {
+		clause = strings.TrimSpace(clause)
+		op := clause[:len(clause)-len(strings.TrimLeft(clause, "<>=!"))]
+		target, err := parseVersion(clause[len(op):])
+		if err != nil {
+			return false, err
+		}
+
+		cmp := v.compare(target)
+		var ok bool
+		switch op {
+		case "", "=", "==":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		default:
+			return false, fmt.Errorf("sen: %q is not a valid version constraint", constraint)
+		}
+
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
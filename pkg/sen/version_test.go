@@ -0,0 +1,48 @@
+package sen_test
+
+import (
+	"testing"
+
+	"github.com/bongnv/sen/pkg/sen"
+)
+
+func TestSatisfiesVersion(t *testing.T) {
+	t.Run("should check versions against constraints", func(t *testing.T) {
+		cases := []struct {
+			version    string
+			constraint string
+			expected   bool
+		}{
+			{"0.2.0", "0.2.0", true},
+			{"v0.2.0", "=0.2", true},
+			{"0.2.1", ">=0.2.0, <1.0.0", true},
+			{"1.0.0", ">=0.2.0, <1.0.0", false},
+			{"0.1.9", ">0.1.10", false},
+			{"0.3.0-rc.1", "<=0.3.0", true},
+			{"0.3.0", "!=0.3.0", false},
+		}
+
+		for _, c := range cases {
+			ok, err := sen.SatisfiesVersion(c.version, c.constraint)
+			if err != nil {
+				t.Errorf("Unexpected err %v", err)
+			}
+
+			if ok != c.expected {
+				t.Errorf("Expected %s %s to be %v", c.version, c.constraint, c.expected)
+			}
+		}
+	})
+
+	t.Run("should return an error if the constraint is invalid", func(t *testing.T) {
+		_, err := sen.SatisfiesVersion("0.2.0", "~>0.2.0")
+		if err == nil {
+			t.Errorf("Expected an error")
+		}
+
+		_, err = sen.SatisfiesVersion("latest", ">=0.2.0")
+		if err == nil {
+			t.Errorf("Expected an error")
+		}
+	})
+}